@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"main/common/config"
 	"main/middlewares"
+	"main/models"
 	"main/routes"
 	"main/services"
+	"main/utils/blobstore"
 	"main/utils/logging"
 	"main/utils/mongodb"
+	"main/utils/notifier"
+	"main/utils/scheduler"
 	"net"
 	"net/http"
 	"os"
@@ -44,6 +48,39 @@ func main() {
 
 	logger.Info("Create services")
 	services := services.NewServices(mongoDBClient, logger)
+	services.UseStatsCache(rdb, cfg.Stats.CacheTTL)
+	services.UseUndoWindow(cfg.Undo.Window)
+	services.UseTimers(rdb)
+
+	blobStore, err := newBlobStore(cfg)
+	if err != nil {
+		panic(err)
+	}
+	services.UseBlobStore(blobStore, models.AttachmentLimits{
+		MaxSize:      cfg.Attachments.MaxSize,
+		AllowedTypes: cfg.Attachments.AllowedTypes,
+	})
+
+	logger.Info("Create indexes")
+	if err := services.CreateIndexes(context.Background()); err != nil {
+		panic(err)
+	}
+
+	logger.Info("Run migrations")
+	if err := services.Migrate(context.Background()); err != nil {
+		panic(err)
+	}
+
+	logger.Info("Start scheduler")
+	reminderNotifier := newNotifier(cfg, logger)
+	jobs := scheduler.NewScheduler(rdb, cfg.Scheduler.Interval, logger)
+	jobs.AddJob("reminders", func(ctx context.Context) error {
+		return services.FireDueReminders(ctx, reminderNotifier)
+	})
+	jobs.AddJob("trash-purge", func(ctx context.Context) error {
+		return services.PurgeTrash(ctx, cfg.Trash.Retention)
+	})
+	go jobs.Run(context.Background())
 
 	logger.Info("Create middlewares")
 	middlewares := middlewares.NewMiddlewares(rdb, logger)
@@ -57,6 +94,28 @@ func main() {
 	start(router.Router, cfg)
 }
 
+func newNotifier(cfg *config.Config, logger *logging.Logger) notifier.Notifier {
+	switch cfg.Notifier.Type {
+	case "webhook":
+		return notifier.NewWebhookNotifier(cfg.Notifier.WebhookURL)
+	case "email":
+		smtp := cfg.Notifier.SMTP
+		return notifier.NewEmailNotifier(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From)
+	default:
+		return notifier.NewLogNotifier(logger)
+	}
+}
+
+func newBlobStore(cfg *config.Config) (blobstore.BlobStore, error) {
+	switch cfg.Attachments.Storage {
+	case "s3":
+		s3 := cfg.Attachments.S3
+		return blobstore.NewS3Store(s3.Endpoint, s3.Region, s3.Bucket, s3.AccessKey, s3.SecretKey)
+	default:
+		return blobstore.NewLocalStore(cfg.Attachments.Dir)
+	}
+}
+
 func start(router *httprouter.Router, cfg *config.Config) {
 	logger := logging.GetLogger()
 
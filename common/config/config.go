@@ -3,6 +3,7 @@ package config
 import (
 	"main/utils/logging"
 	"sync"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -28,6 +29,42 @@ type Config struct {
 		Passwod string `yaml:"password" env-default:""`
 		DB      int    `yaml:"DB" env-default:"0"`
 	} `yaml:"redis"`
+	Scheduler struct {
+		Interval time.Duration `yaml:"interval" env-default:"30s"`
+	} `yaml:"scheduler"`
+	Trash struct {
+		Retention time.Duration `yaml:"retention" env-default:"720h"`
+	} `yaml:"trash"`
+	Stats struct {
+		CacheTTL time.Duration `yaml:"cache_ttl" env-default:"1m"`
+	} `yaml:"stats"`
+	Undo struct {
+		Window time.Duration `yaml:"window" env-default:"10m"`
+	} `yaml:"undo"`
+	Attachments struct {
+		Storage      string   `yaml:"storage" env-default:"local"`
+		Dir          string   `yaml:"dir" env-default:"attachments"`
+		MaxSize      int64    `yaml:"max_size" env-default:"10485760"`
+		AllowedTypes []string `yaml:"allowed_types" env-default:"image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"`
+		S3           struct {
+			Endpoint  string `yaml:"endpoint"`
+			Region    string `yaml:"region" env-default:"us-east-1"`
+			Bucket    string `yaml:"bucket"`
+			AccessKey string `yaml:"access_key"`
+			SecretKey string `yaml:"secret_key"`
+		} `yaml:"s3"`
+	} `yaml:"attachments"`
+	Notifier struct {
+		Type       string `yaml:"type" env-default:"log"`
+		WebhookURL string `yaml:"webhook_url"`
+		SMTP       struct {
+			Host     string `yaml:"host" env-default:"localhost"`
+			Port     string `yaml:"port" env-default:"25"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+			From     string `yaml:"from"`
+		} `yaml:"smtp"`
+	} `yaml:"notifier"`
 }
 
 var instance *Config
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const (
+	ActivityCreate   = "create"
+	ActivityUpdate   = "update"
+	ActivityComplete = "complete"
+	ActivityDelete   = "delete"
+)
+
+// untrackedFields are task fields that change as a side effect of other
+// operations and are left out of activity diffs.
+var untrackedFields = map[string]bool{
+	"id":             true,
+	"user_id":        true,
+	"position":       true,
+	"progress":       true,
+	"series_id":      true,
+	"occurrence":     true,
+	"comments_count": true,
+	"is_blocked":     true,
+	"deleted_at":     true,
+	"UpdatedAt":      true,
+	"CreatedAt":      true,
+}
+
+// Activity records a change of a task by a user. UserID is the owner of the
+// task and ActorID the user who made the change. Title is the title of the
+// task at that time, so entries stay readable after renames.
+type Activity struct {
+	ID        string        `json:"id" bson:"_id,omitempty"`
+	TaskID    string        `json:"task_id" bson:"task_id"`
+	ListID    string        `json:"list_id" bson:"list_id"`
+	UserID    string        `json:"user_id" bson:"user_id"`
+	ActorID   string        `json:"actor_id" bson:"actor_id"`
+	Action    string        `json:"action" bson:"action"`
+	Title     string        `json:"title" bson:"title"`
+	Changes   []FieldChange `json:"changes" bson:"changes"`
+	CreatedAt time.Time     `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// FieldChange is the value of a task field, named as in the API, before and
+// after a change.
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// ActivityFilter holds the query string options of the activity feed.
+type ActivityFilter struct {
+	ListID  string `validate:"regexp=^([0-9a-f]{24})?$"`
+	ActorID string `validate:"regexp=^([0-9a-f]{24})?$"`
+}
+
+// ActivityPage is one page of an activity feed, newest first. Total counts
+// all entries of the feed.
+type ActivityPage struct {
+	Activity []Activity `json:"activity"`
+	Total    int64      `json:"total"`
+	Limit    int64      `json:"limit"`
+	Offset   int64      `json:"offset"`
+}
+
+// NewActivity records that actor changed before into after. A created task
+// has an empty before, a deleted one an empty after. Updates that complete
+// the task are recorded as completions. It returns nil when nothing tracked
+// has changed.
+func NewActivity(actor string, before, after Task) *Activity {
+	activity := &Activity{ActorID: actor, CreatedAt: time.Now()}
+
+	task := after
+	switch {
+	case before.ID == "":
+		activity.Action = ActivityCreate
+	case after.ID == "":
+		activity.Action = ActivityDelete
+		task = before
+	case !before.Complete && after.Complete:
+		activity.Action = ActivityComplete
+	default:
+		activity.Action = ActivityUpdate
+	}
+
+	activity.TaskID = task.ID
+	activity.ListID = task.ListID
+	activity.UserID = task.UserID
+	activity.Title = task.Title
+
+	if activity.Action != ActivityDelete {
+		activity.Changes = DiffTasks(before, after)
+		if activity.Action == ActivityUpdate && len(activity.Changes) == 0 {
+			return nil
+		}
+	}
+	if activity.Changes == nil {
+		activity.Changes = []FieldChange{}
+	}
+
+	return activity
+}
+
+// DiffTasks returns the tracked fields that differ between two tasks,
+// sorted by name.
+func DiffTasks(before, after Task) (changes []FieldChange) {
+	beforeFields, afterFields := taskFields(before), taskFields(after)
+
+	for field, value := range afterFields {
+		if untrackedFields[field] || bytes.Equal(value, beforeFields[field]) {
+			continue
+		}
+
+		change := FieldChange{Field: field}
+		json.Unmarshal(beforeFields[field], &change.Before)
+		json.Unmarshal(value, &change.After)
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// taskFields returns the JSON encoded fields of a task. Dates are compared
+// in UTC and at the millisecond precision MongoDB stores them with.
+func taskFields(task Task) map[string]json.RawMessage {
+	for _, date := range []**time.Time{&task.StartAt, &task.DueAt} {
+		if *date != nil {
+			normalized := (*date).UTC().Truncate(time.Millisecond)
+			*date = &normalized
+		}
+	}
+	if task.Subs == nil {
+		task.Subs = []SubTask{}
+	}
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+
+	fields := map[string]json.RawMessage{}
+	raw, _ := json.Marshal(task)
+	json.Unmarshal(raw, &fields)
+
+	return fields
+}
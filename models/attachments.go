@@ -0,0 +1,100 @@
+package models
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const maxFileNameLength = 255
+
+// Attachment is a file uploaded to a task. Its contents live in the blob
+// store under Key, UserID is the owner of the task like on Task.
+type Attachment struct {
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	TaskID      string    `json:"task_id" bson:"task_id"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	UploadedBy  string    `json:"uploaded_by" bson:"uploaded_by"`
+	Name        string    `json:"name" bson:"name"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	Key         string    `json:"-" bson:"key"`
+	CreatedAt   time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type CreateAttachmentDTO struct {
+	TaskID      string    `json:"task_id" bson:"task_id"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	UploadedBy  string    `json:"uploaded_by" bson:"uploaded_by"`
+	Name        string    `json:"name" bson:"name"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	Key         string    `json:"-" bson:"key"`
+	CreatedAt   time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// AttachmentLimits restricts uploads. AllowedTypes holds media types such as
+// "application/pdf" or whole families such as "image/*".
+type AttachmentLimits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+// Allows reports whether files of contentType may be uploaded.
+func (l AttachmentLimits) Allows(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range l.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CleanFileName keeps the base name of an uploaded file without control
+// characters, so it can be sent back in a Content-Disposition header.
+func CleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		name = string(runes[:maxFileNameLength])
+	}
+
+	return name
+}
+
+func (a CreateAttachmentDTO) Build(id string) *Attachment {
+	return &Attachment{
+		ID:          id,
+		TaskID:      a.TaskID,
+		UserID:      a.UserID,
+		UploadedBy:  a.UploadedBy,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Key:         a.Key,
+		CreatedAt:   a.CreatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import "encoding/json"
+
+// BatchRB is an ordered list of mutations. Operations can refer to items
+// created earlier in the same batch as "$<temp_id>" in id and list_id.
+type BatchRB struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations" validate:"min=1,max=100"`
+}
+
+type BatchOperation struct {
+	Op     string          `json:"op" validate:"regexp=^(create|update|delete)$"`
+	Type   string          `json:"type" validate:"regexp=^(task|list)$"`
+	TempID string          `json:"temp_id"`
+	ID     string          `json:"id"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// BatchResult is the outcome of one operation. When an atomic batch fails,
+// the operations that succeeded before it are RolledBack and the ones after
+// it are not run.
+type BatchResult struct {
+	TempID     string      `json:"temp_id,omitempty"`
+	ID         string      `json:"id,omitempty"`
+	Success    bool        `json:"success"`
+	RolledBack bool        `json:"rolled_back,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// RollBack marks the results of an atomic batch that did not commit.
+// Operations without a result were not run.
+func RollBack(results []BatchResult, operations []BatchOperation) []BatchResult {
+	rolledBack := make([]BatchResult, len(operations))
+
+	for i, op := range operations {
+		if i >= len(results) {
+			rolledBack[i] = BatchResult{TempID: op.TempID, Error: "not run"}
+			continue
+		}
+
+		rolledBack[i] = BatchResult{TempID: results[i].TempID, Error: results[i].Error}
+		if results[i].Success {
+			rolledBack[i].RolledBack = true
+		}
+	}
+
+	return rolledBack
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// mentionPattern matches @mentions of users by their email, as in
+// "@alice@example.com".
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.+-]+@[\w-]+(?:\.[\w-]+)+)`)
+
+// Comment is a message in the discussion thread of a task. Body is Markdown
+// and is rendered by clients. Earlier versions of an edited body are kept in
+// History, oldest first.
+type Comment struct {
+	ID        string            `json:"id" bson:"_id,omitempty"`
+	TaskID    string            `json:"task_id" bson:"task_id"`
+	AuthorID  string            `json:"author_id" bson:"author_id"`
+	Body      string            `json:"body" bson:"body"`
+	Mentions  []string          `json:"mentions" bson:"mentions"`
+	History   []CommentRevision `json:"history" bson:"history"`
+	EditedAt  *time.Time        `json:"edited_at" bson:"edited_at"`
+	CreatedAt time.Time         `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// CommentRevision is a replaced body of a comment and when it was written.
+type CommentRevision struct {
+	Body      string    `json:"body" bson:"body"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type CreateCommentRB struct {
+	Body string `json:"body" bson:"body" validate:"nonzero,max=10000"`
+}
+
+type CreateCommentDTO struct {
+	TaskID    string            `json:"task_id" bson:"task_id"`
+	AuthorID  string            `json:"author_id" bson:"author_id"`
+	Body      string            `json:"body" bson:"body"`
+	Mentions  []string          `json:"mentions" bson:"mentions"`
+	History   []CommentRevision `json:"history" bson:"history"`
+	EditedAt  *time.Time        `json:"edited_at" bson:"edited_at"`
+	CreatedAt time.Time         `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type UpdateCommentRB struct {
+	Body string `json:"body" bson:"body" validate:"nonzero,max=10000"`
+}
+
+// CommentsPage is one page of the comments of a task. Total counts all of
+// them.
+type CommentsPage struct {
+	Comments []Comment `json:"comments"`
+	Total    int64     `json:"total"`
+	Limit    int64     `json:"limit"`
+	Offset   int64     `json:"offset"`
+}
+
+// Page selects a slice of a listing, from ?limit= and ?offset=.
+type Page struct {
+	Limit  int64
+	Offset int64
+}
+
+func ParsePage(limit string, offset string) (page Page, err error) {
+	page.Limit = defaultPageLimit
+	if limit != "" {
+		page.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || page.Limit < 1 || page.Limit > maxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+	}
+
+	if offset != "" {
+		page.Offset, err = strconv.ParseInt(offset, 10, 64)
+		if err != nil || page.Offset < 0 {
+			return page, fmt.Errorf("offset must not be negative")
+		}
+	}
+
+	return page, nil
+}
+
+// ParseMentions returns the lowercased emails mentioned in a comment body,
+// without duplicates.
+func ParseMentions(body string) (emails []string) {
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+
+	return emails
+}
+
+func (c CreateCommentRB) Build(tid string, uid string) *CreateCommentDTO {
+	return &CreateCommentDTO{
+		TaskID:    tid,
+		AuthorID:  uid,
+		Body:      c.Body,
+		Mentions:  []string{},
+		History:   []CommentRevision{},
+		CreatedAt: time.Now(),
+	}
+}
+
+func (c CreateCommentDTO) Build(id string) *Comment {
+	return &Comment{
+		ID:        id,
+		TaskID:    c.TaskID,
+		AuthorID:  c.AuthorID,
+		Body:      c.Body,
+		Mentions:  c.Mentions,
+		History:   c.History,
+		EditedAt:  c.EditedAt,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+// Revision returns the current body of the comment as a revision.
+func (c Comment) Revision() CommentRevision {
+	revision := CommentRevision{Body: c.Body, CreatedAt: c.CreatedAt}
+	if c.EditedAt != nil {
+		revision.CreatedAt = *c.EditedAt
+	}
+
+	return revision
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Dependency is an edge between two tasks: TaskID can not start until
+// BlockerID is done. UserID is the user who added it. With Notify set the
+// owner of the task is notified once completing the blocker unblocks it.
+type Dependency struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	TaskID    string    `json:"task_id" bson:"task_id"`
+	BlockerID string    `json:"blocker_id" bson:"blocker_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Notify    bool      `json:"notify" bson:"notify"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type CreateDependencyRB struct {
+	BlockerID string `json:"blocker_id" bson:"blocker_id" validate:"nonzero,len=24"`
+	Notify    bool   `json:"notify" bson:"notify"`
+}
+
+type CreateDependencyDTO struct {
+	TaskID    string    `json:"task_id" bson:"task_id"`
+	BlockerID string    `json:"blocker_id" bson:"blocker_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Notify    bool      `json:"notify" bson:"notify"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+func (r CreateDependencyRB) Build(tid string, uid string) *CreateDependencyDTO {
+	return &CreateDependencyDTO{
+		TaskID:    tid,
+		BlockerID: r.BlockerID,
+		UserID:    uid,
+		Notify:    r.Notify,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (r CreateDependencyDTO) Build(id string) *Dependency {
+	return &Dependency{
+		ID:        id,
+		TaskID:    r.TaskID,
+		BlockerID: r.BlockerID,
+		UserID:    r.UserID,
+		Notify:    r.Notify,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// TaskDependencies lists the tasks a task waits for and the tasks waiting
+// for it. Tasks the user can not see are left out, but still count for
+// IsBlocked.
+type TaskDependencies struct {
+	TaskID    string `json:"task_id"`
+	IsBlocked bool   `json:"is_blocked"`
+	BlockedBy []Task `json:"blocked_by"`
+	Blocks    []Task `json:"blocks"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type Folder struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	UserID    string    `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	Name      string    `json:"name" bson:"name" validate:"nonzero,max=64"`
+	Position  string    `json:"position" bson:"position"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+type CreateFolderRB struct {
+	Name string `json:"name" bson:"name" validate:"nonzero,max=64"`
+}
+
+type CreateFolderDTO struct {
+	UserID    string    `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	Name      string    `json:"name" bson:"name" validate:"nonzero,max=64"`
+	Position  string    `json:"position" bson:"position"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+type UpdateFolderRB struct {
+	ID   string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+	Name string `json:"name" bson:"name" validate:"nonzero,max=64"`
+}
+
+type UpdateFolderDTO struct {
+	Name      string    `json:"name" bson:"name"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt"`
+}
+
+type DeleteFolderDTO struct {
+	ID string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+}
+
+// FolderNode is a folder with its lists in the tree of tasks lists.
+type FolderNode struct {
+	Folder
+	TasksLists []TasksList `json:"tasks_lists"`
+}
+
+// TasksListsTree groups lists by folder. Lists outside any folder are kept
+// at the root.
+type TasksListsTree struct {
+	Folders    []FolderNode `json:"folders"`
+	TasksLists []TasksList  `json:"tasks_lists"`
+}
+
+func (f CreateFolderRB) Build(uid string) *CreateFolderDTO {
+	return &CreateFolderDTO{
+		UserID:    uid,
+		Name:      strings.TrimSpace(f.Name),
+		UpdatedAt: time.Now(),
+		CreatedAt: time.Now(),
+	}
+}
+
+func (f CreateFolderDTO) Build(id string) *Folder {
+	return &Folder{
+		ID:        id,
+		UserID:    f.UserID,
+		Name:      f.Name,
+		Position:  f.Position,
+		UpdatedAt: f.UpdatedAt,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func (f UpdateFolderRB) Build() *UpdateFolderDTO {
+	return &UpdateFolderDTO{
+		Name:      strings.TrimSpace(f.Name),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// BuildTasksListsTree places every list under its folder, keeping the order
+// of both slices. Lists of unknown folders go to the root.
+func BuildTasksListsTree(folders []Folder, tasksLists []TasksList) TasksListsTree {
+	tree := TasksListsTree{Folders: make([]FolderNode, len(folders)), TasksLists: []TasksList{}}
+	index := map[string]int{}
+
+	for i, folder := range folders {
+		tree.Folders[i] = FolderNode{Folder: folder, TasksLists: []TasksList{}}
+		index[folder.ID] = i
+	}
+
+	for _, tasksList := range tasksLists {
+		if i, ok := index[tasksList.FolderID]; ok {
+			tree.Folders[i].TasksLists = append(tree.Folders[i].TasksLists, tasksList)
+		} else {
+			tree.TasksLists = append(tree.TasksLists, tasksList)
+		}
+	}
+
+	return tree
+}
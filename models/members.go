@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Roles of a list member, from least to most privileged. Viewers can read
+// the list and its tasks, editors can also change the tasks and owners can
+// also change the list and its members.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// RoleAllows reports whether role grants at least the rights of required.
+func RoleAllows(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required]
+}
+
+const (
+	MemberPending  = "pending"
+	MemberAccepted = "accepted"
+	MemberDeclined = "declined"
+)
+
+// Member is an invitation to a tasks list. UserID is set once the invited
+// user accepts.
+type Member struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	ListID    string    `json:"list_id" bson:"list_id"`
+	OwnerID   string    `json:"owner_id" bson:"owner_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Email     string    `json:"email" bson:"email"`
+	Role      string    `json:"role" bson:"role"`
+	Status    string    `json:"status" bson:"status"`
+	InvitedBy string    `json:"invited_by" bson:"invited_by"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type InviteMemberRB struct {
+	Email string `json:"email" bson:"email" validate:"nonzero,max=254,regexp=^[^@ ]+@[^@ ]+$"`
+	Role  string `json:"role" bson:"role" validate:"regexp=^(viewer|editor|owner)$"`
+}
+
+type InviteMemberDTO struct {
+	ListID    string    `json:"list_id" bson:"list_id"`
+	OwnerID   string    `json:"owner_id" bson:"owner_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Email     string    `json:"email" bson:"email"`
+	Role      string    `json:"role" bson:"role"`
+	Status    string    `json:"status" bson:"status"`
+	InvitedBy string    `json:"invited_by" bson:"invited_by"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type UpdateMemberRB struct {
+	Role string `json:"role" bson:"role" validate:"regexp=^(viewer|editor|owner)$"`
+}
+
+// TasksScope selects the tasks a listing covers: the user's own tasks and
+// those of the lists shared with them, minus the excluded lists.
+type TasksScope struct {
+	UserID         string
+	SharedListIDs  []string
+	ExcludeListIDs []string
+}
+
+func (m InviteMemberRB) Build(list TasksList, uid string) *InviteMemberDTO {
+	return &InviteMemberDTO{
+		ListID:    list.ID,
+		OwnerID:   list.UserID,
+		Email:     strings.ToLower(strings.TrimSpace(m.Email)),
+		Role:      m.Role,
+		Status:    MemberPending,
+		InvitedBy: uid,
+		UpdatedAt: time.Now(),
+		CreatedAt: time.Now(),
+	}
+}
+
+func (m InviteMemberDTO) Build(id string) *Member {
+	return &Member{
+		ID:        id,
+		ListID:    m.ListID,
+		OwnerID:   m.OwnerID,
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		Status:    m.Status,
+		InvitedBy: m.InvitedBy,
+		UpdatedAt: m.UpdatedAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
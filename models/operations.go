@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+const (
+	OperationDone   = "done"
+	OperationUndone = "undone"
+)
+
+// Operation is an entry of the undo log of a user: the tasks one request
+// changed, with their state before and after it. Undoing restores Before,
+// redoing restores After.
+type Operation struct {
+	ID        string       `json:"id" bson:"_id,omitempty"`
+	UserID    string       `json:"user_id" bson:"user_id"`
+	Changes   []TaskChange `json:"changes" bson:"changes"`
+	State     string       `json:"state" bson:"state"`
+	UndoneAt  *time.Time   `json:"undone_at" bson:"undone_at"`
+	CreatedAt time.Time    `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// TaskChange is the state of a task around an operation. Before is nil for
+// a created task, After is nil for a task moved to the trash.
+type TaskChange struct {
+	TaskID string `json:"task_id" bson:"task_id"`
+	Before *Task  `json:"before" bson:"before"`
+	After  *Task  `json:"after" bson:"after"`
+}
+
+// UndoResult lists the operations that were undone or redone and the tasks
+// they changed, in their new state.
+type UndoResult struct {
+	Operations []string `json:"operations"`
+	Tasks      []Task   `json:"tasks"`
+}
+
+// NewOperation builds the log entry for tasks changed from before to after,
+// which are passed like to NewActivity. Tasks without tracked changes are
+// left out and nil is returned when none are left.
+func NewOperation(uid string, before []Task, after []Task) *Operation {
+	operation := &Operation{UserID: uid, State: OperationDone, CreatedAt: time.Now()}
+
+	for i := range before {
+		change := TaskChange{TaskID: after[i].ID}
+		if before[i].ID != "" {
+			change.TaskID = before[i].ID
+			change.Before = &before[i]
+		}
+		if after[i].ID != "" {
+			change.After = &after[i]
+		}
+
+		if change.Before != nil && change.After != nil && len(DiffTasks(*change.Before, *change.After)) == 0 {
+			continue
+		}
+
+		operation.Changes = append(operation.Changes, change)
+	}
+
+	if len(operation.Changes) == 0 {
+		return nil
+	}
+
+	return operation
+}
+
+// TaskInState reports whether current is the state a change left the task
+// in, so reverting the change does not lose edits made since. A nil state
+// means the task was moved to the trash, a nil current that it is gone.
+func TaskInState(current *Task, state *Task) bool {
+	if current == nil {
+		return false
+	}
+
+	if state == nil {
+		return current.DeletedAt != nil
+	}
+
+	return current.DeletedAt == nil && len(DiffTasks(*state, *current)) == 0
+}
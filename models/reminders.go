@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// ReminderUnblocked marks reminders sent when the last open blocker of a
+// task was completed, instead of at a time chosen by the user.
+const ReminderUnblocked = "unblocked"
+
+type Reminder struct {
+	ID            string     `json:"id" bson:"_id,omitempty"`
+	UserID        string     `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	TaskID        string     `json:"task_id" bson:"task_id" validate:"nonzero,len=24"`
+	Kind          string     `json:"kind,omitempty" bson:"kind,omitempty"`
+	BeforeMinutes *int       `json:"before_minutes" bson:"before_minutes"`
+	At            *time.Time `json:"at" bson:"at"`
+	FireAt        *time.Time `json:"fire_at" bson:"fire_at"`
+	Fired         bool       `json:"fired" bson:"fired"`
+	FiredAt       *time.Time `json:"fired_at" bson:"fired_at"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	LockedUntil   time.Time  `json:"-" bson:"locked_until"`
+	CreatedAt     time.Time  `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+type CreateReminderRB struct {
+	TaskID        string     `json:"task_id" bson:"task_id" validate:"nonzero,len=24"`
+	BeforeMinutes *int       `json:"before_minutes" bson:"before_minutes"`
+	At            *time.Time `json:"at" bson:"at"`
+}
+
+type CreateReminderDTO struct {
+	UserID        string     `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	TaskID        string     `json:"task_id" bson:"task_id" validate:"nonzero,len=24"`
+	Kind          string     `json:"kind,omitempty" bson:"kind,omitempty"`
+	BeforeMinutes *int       `json:"before_minutes" bson:"before_minutes"`
+	At            *time.Time `json:"at" bson:"at"`
+	FireAt        *time.Time `json:"fire_at" bson:"fire_at"`
+	Fired         bool       `json:"fired" bson:"fired"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	LockedUntil   time.Time  `json:"-" bson:"locked_until"`
+	CreatedAt     time.Time  `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+type DeleteReminderDTO struct {
+	ID string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+}
+
+func (r CreateReminderRB) Build(uid string, task Task) *CreateReminderDTO {
+	return &CreateReminderDTO{
+		UserID:        uid,
+		TaskID:        r.TaskID,
+		BeforeMinutes: r.BeforeMinutes,
+		At:            r.At,
+		FireAt:        ReminderFireAt(r.BeforeMinutes, r.At, task.DueInstant()),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (r CreateReminderDTO) Build(id string) *Reminder {
+	return &Reminder{
+		ID:            id,
+		UserID:        r.UserID,
+		TaskID:        r.TaskID,
+		Kind:          r.Kind,
+		BeforeMinutes: r.BeforeMinutes,
+		At:            r.At,
+		FireAt:        r.FireAt,
+		Fired:         r.Fired,
+		Attempts:      r.Attempts,
+		LockedUntil:   r.LockedUntil,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// ReminderFireAt returns when a reminder has to fire. Relative reminders
+// follow the task due date and stay unscheduled while the task has none.
+func ReminderFireAt(beforeMinutes *int, at *time.Time, dueAt *time.Time) *time.Time {
+	if at != nil {
+		fireAt := at.UTC()
+		return &fireAt
+	}
+
+	if beforeMinutes == nil || dueAt == nil {
+		return nil
+	}
+
+	fireAt := dueAt.Add(-time.Duration(*beforeMinutes) * time.Minute).UTC()
+
+	return &fireAt
+}
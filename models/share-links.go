@@ -0,0 +1,152 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ShareLink gives read-only access to a tasks list without an account. Only
+// a hash of the token is stored, the token itself is returned once when the
+// link is created.
+type ShareLink struct {
+	ID           string     `json:"id" bson:"_id,omitempty"`
+	ListID       string     `json:"list_id" bson:"list_id"`
+	OwnerID      string     `json:"owner_id" bson:"owner_id"`
+	Token        string     `json:"token,omitempty" bson:"-"`
+	TokenHash    string     `json:"-" bson:"token_hash"`
+	PasswordHash string     `json:"-" bson:"password_hash"`
+	Protected    bool       `json:"protected" bson:"protected"`
+	ExpiresAt    *time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedBy    string     `json:"created_by" bson:"created_by"`
+	CreatedAt    time.Time  `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+type CreateShareLinkRB struct {
+	ExpiresAt *time.Time `json:"expires_at" bson:"expires_at"`
+	Password  string     `json:"password" bson:"password" validate:"max=72"`
+}
+
+type CreateShareLinkDTO struct {
+	ListID       string     `json:"list_id" bson:"list_id"`
+	OwnerID      string     `json:"owner_id" bson:"owner_id"`
+	TokenHash    string     `json:"-" bson:"token_hash"`
+	PasswordHash string     `json:"-" bson:"password_hash"`
+	Protected    bool       `json:"protected" bson:"protected"`
+	ExpiresAt    *time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedBy    string     `json:"created_by" bson:"created_by"`
+	CreatedAt    time.Time  `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// SharedTasksList is the public view served for a share link.
+type SharedTasksList struct {
+	Name  string       `json:"name"`
+	Color string       `json:"color"`
+	Tasks []SharedTask `json:"tasks"`
+}
+
+// SharedTask is the public view of a task. It leaves out everything about
+// the owner, collaborators and how the list is organised.
+type SharedTask struct {
+	Title     string          `json:"title"`
+	Note      string          `json:"note"`
+	Subs      []SharedSubTask `json:"subs"`
+	Complete  bool            `json:"complete"`
+	DueAt     *time.Time      `json:"due_at"`
+	DueAllDay bool            `json:"due_all_day"`
+	Priority  Priority        `json:"priority"`
+}
+
+type SharedSubTask struct {
+	Title    string `json:"title"`
+	Complete bool   `json:"complete"`
+}
+
+// NewSharedTasksList builds the public view of a list and its tasks.
+func NewSharedTasksList(list TasksList, tasks []Task) SharedTasksList {
+	shared := SharedTasksList{Name: list.Name, Color: list.Color, Tasks: make([]SharedTask, len(tasks))}
+
+	for i, task := range tasks {
+		subs := make([]SharedSubTask, len(task.Subs))
+		for j, sub := range task.Subs {
+			subs[j] = SharedSubTask{Title: sub.Title, Complete: sub.Complete}
+		}
+
+		shared.Tasks[i] = SharedTask{
+			Title:     task.Title,
+			Note:      task.Note,
+			Subs:      subs,
+			Complete:  task.Complete,
+			DueAt:     task.DueAt,
+			DueAllDay: task.DueAllDay,
+			Priority:  task.Priority,
+		}
+	}
+
+	return shared
+}
+
+// HashShareToken returns the form a share token is stored and looked up in.
+func HashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Build generates the token of a new link and returns it with the link.
+func (l CreateShareLinkRB) Build(list TasksList, uid string) (*CreateShareLinkDTO, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link := &CreateShareLinkDTO{
+		ListID:    list.ID,
+		OwnerID:   list.UserID,
+		TokenHash: HashShareToken(token),
+		ExpiresAt: l.ExpiresAt,
+		CreatedBy: uid,
+		CreatedAt: time.Now(),
+	}
+
+	if l.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(l.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", err
+		}
+		link.PasswordHash = string(hash)
+		link.Protected = true
+	}
+
+	return link, token, nil
+}
+
+func (l CreateShareLinkDTO) Build(id string, token string) *ShareLink {
+	return &ShareLink{
+		ID:           id,
+		ListID:       l.ListID,
+		OwnerID:      l.OwnerID,
+		Token:        token,
+		TokenHash:    l.TokenHash,
+		PasswordHash: l.PasswordHash,
+		Protected:    l.Protected,
+		ExpiresAt:    l.ExpiresAt,
+		CreatedBy:    l.CreatedBy,
+		CreatedAt:    l.CreatedAt,
+	}
+}
+
+// CheckPassword reports whether password opens the link. Links without a
+// password accept any.
+func (l ShareLink) CheckPassword(password string) bool {
+	if !l.Protected {
+		return true
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(l.PasswordHash), []byte(password)) == nil
+}
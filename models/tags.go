@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type Tag struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	UserID    string    `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	Name      string    `json:"name" bson:"name" validate:"nonzero,max=64"`
+	Color     string    `json:"color" bson:"color"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+type CreateTagRB struct {
+	Name  string `json:"name" bson:"name" validate:"nonzero,max=64"`
+	Color string `json:"color" bson:"color"`
+}
+
+type CreateTagDTO struct {
+	UserID    string    `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	Name      string    `json:"name" bson:"name" validate:"nonzero,max=64"`
+	Color     string    `json:"color" bson:"color"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+type UpdateTagRB struct {
+	ID    string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+	Name  string `json:"name" bson:"name" validate:"nonzero,max=64"`
+	Color string `json:"color" bson:"color"`
+}
+
+type UpdateTagDTO struct {
+	Name      string    `json:"name" bson:"name"`
+	Color     string    `json:"color" bson:"color"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt"`
+}
+
+type DeleteTagDTO struct {
+	ID string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+}
+
+func (t CreateTagRB) Build(uid string) *CreateTagDTO {
+	return &CreateTagDTO{
+		UserID:    uid,
+		Name:      strings.TrimSpace(t.Name),
+		Color:     t.Color,
+		UpdatedAt: time.Now(),
+		CreatedAt: time.Now(),
+	}
+}
+
+func (t CreateTagDTO) Build(id string) *Tag {
+	return &Tag{
+		ID:        id,
+		UserID:    t.UserID,
+		Name:      t.Name,
+		Color:     t.Color,
+		UpdatedAt: t.UpdatedAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+func (t UpdateTagRB) Build() *UpdateTagDTO {
+	return &UpdateTagDTO{
+		Name:      strings.TrimSpace(t.Name),
+		Color:     t.Color,
+		UpdatedAt: time.Now(),
+	}
+}
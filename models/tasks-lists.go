@@ -1,21 +1,43 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type TasksList struct {
-	ID        string    `json:"id" bson:"_id,omitempty"`
-	UserID    string    `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
-	Name      string    `json:"name" bson:"name" validate:"nonzero"`
-	Color     string    `json:"color" bson:"color" validate:"nonzero"`
-	Hidden    bool      `json:"hidden" bson:"hidden" validate:"nonnil"`
-	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
-	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+	ID         string           `json:"id" bson:"_id,omitempty"`
+	UserID     string           `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	Name       string           `json:"name" bson:"name" validate:"nonzero"`
+	Color      string           `json:"color" bson:"color" validate:"nonzero"`
+	Hidden     bool             `json:"hidden" bson:"hidden" validate:"nonnil"`
+	Inbox      bool             `json:"inbox" bson:"inbox"`
+	FolderID   string           `json:"folder_id" bson:"folder_id"`
+	Position   string           `json:"position" bson:"position"`
+	Statuses   []WorkflowStatus `json:"statuses" bson:"statuses"`
+	ArchivedAt *time.Time       `json:"archived_at" bson:"archived_at"`
+	DeletedAt  *time.Time       `json:"deleted_at" bson:"deleted_at"`
+	Stats      *TasksListStats  `json:"stats,omitempty" bson:"-"`
+	Role       string           `json:"role,omitempty" bson:"-"`
+	UpdatedAt  time.Time        `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt  time.Time        `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+// TasksListStats counts the tasks of a list that are not in the trash.
+type TasksListStats struct {
+	Total        int        `json:"total" bson:"total"`
+	Completed    int        `json:"completed" bson:"completed"`
+	Overdue      int        `json:"overdue" bson:"overdue"`
+	DueToday     int        `json:"due_today" bson:"due_today"`
+	LastActivity *time.Time `json:"last_activity" bson:"last_activity"`
 }
 
 type CreateTasksListRB struct {
-	Name   string `json:"name" bson:"name" validate:"nonzero"`
-	Color  string `json:"color" bson:"color" validate:"nonzero"`
-	Hidden bool   `json:"hidden" bson:"hidden" validate:"nonnil"`
+	Name     string `json:"name" bson:"name" validate:"nonzero"`
+	Color    string `json:"color" bson:"color" validate:"nonzero"`
+	Hidden   bool   `json:"hidden" bson:"hidden" validate:"nonnil"`
+	FolderID string `json:"folder_id" bson:"folder_id" validate:"regexp=^([0-9a-f]{24})?$"`
 }
 
 type CreateTasksListDTO struct {
@@ -23,26 +45,68 @@ type CreateTasksListDTO struct {
 	Name      string    `json:"name" bson:"name" validate:"nonzero"`
 	Color     string    `json:"color" bson:"color" validate:"nonzero"`
 	Hidden    bool      `json:"hidden" bson:"hidden" validate:"nonnil"`
+	Inbox     bool      `json:"inbox" bson:"inbox"`
+	FolderID  string    `json:"folder_id" bson:"folder_id"`
+	Position  string    `json:"position" bson:"position"`
 	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
 	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
 }
 
 type UpdateTasksListRB struct {
-	ID     string `json:"id" bson:"_id,omitempty" validate:"nonzero"`
-	Name   string `json:"name" bson:"name"`
-	Color  string `json:"color" bson:"color"`
-	Hidden bool   `json:"hidden" bson:"hidden"`
+	ID       string `json:"id" bson:"_id,omitempty" validate:"nonzero"`
+	Name     string `json:"name" bson:"name"`
+	Color    string `json:"color" bson:"color"`
+	Hidden   bool   `json:"hidden" bson:"hidden"`
+	FolderID string `json:"folder_id" bson:"folder_id" validate:"regexp=^([0-9a-f]{24})?$"`
 }
 
 type UpdateTasksListDTO struct {
 	Name      string    `json:"name" bson:"name"`
 	Color     string    `json:"color" bson:"color"`
 	Hidden    bool      `json:"hidden" bson:"hidden"`
+	FolderID  string    `json:"folder_id" bson:"folder_id"`
 	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt"`
 }
 
+// DeleteTasksListDTO selects what happens to the tasks of the deleted list:
+// "cascade" trashes them with the list, "move" moves them to TargetListID
+// and "refuse", the default, fails unless the list is empty.
 type DeleteTasksListDTO struct {
-	ID string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+	ID           string `json:"id" bson:"_id,omitempty" validate:"nonzero,len=24"`
+	Mode         string `json:"mode" bson:"mode" validate:"regexp=^(|cascade|move|refuse)$"`
+	TargetListID string `json:"target_list_id" bson:"target_list_id" validate:"regexp=^([0-9a-f]{24})?$"`
+}
+
+// ListsInclude holds the ?include= options. By default hidden lists are left
+// out of list listings and archived lists together with their tasks are
+// left out of every listing and view.
+type ListsInclude struct {
+	Archived bool
+	Hidden   bool
+}
+
+func ParseListsInclude(v string) (include ListsInclude, err error) {
+	if v == "" {
+		return include, nil
+	}
+
+	for _, option := range strings.Split(v, ",") {
+		switch option {
+		case "archived":
+			include.Archived = true
+		case "hidden":
+			include.Hidden = true
+		default:
+			return include, fmt.Errorf("unknown include option %q", option)
+		}
+	}
+
+	return include, nil
+}
+
+type ArchivedCounts struct {
+	TasksLists int64 `json:"tasks_lists"`
+	Tasks      int64 `json:"tasks"`
 }
 
 func (task CreateTasksListRB) Build(uid string) *CreateTasksListDTO {
@@ -51,6 +115,20 @@ func (task CreateTasksListRB) Build(uid string) *CreateTasksListDTO {
 		Name:      task.Name,
 		Color:     task.Color,
 		Hidden:    task.Hidden,
+		FolderID:  task.FolderID,
+		UpdatedAt: time.Now(),
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewInbox builds the protected list every user gets at registration. Tasks
+// created without a list end up there.
+func NewInbox(uid string) *CreateTasksListDTO {
+	return &CreateTasksListDTO{
+		UserID:    uid,
+		Name:      "Inbox",
+		Color:     "#808080",
+		Inbox:     true,
 		UpdatedAt: time.Now(),
 		CreatedAt: time.Now(),
 	}
@@ -63,6 +141,9 @@ func (task CreateTasksListDTO) Build(id string) *TasksList {
 		Name:      task.Name,
 		Color:     task.Color,
 		Hidden:    task.Hidden,
+		Inbox:     task.Inbox,
+		FolderID:  task.FolderID,
+		Position:  task.Position,
 		UpdatedAt: task.UpdatedAt,
 		CreatedAt: task.CreatedAt,
 	}
@@ -73,6 +154,7 @@ func (task UpdateTasksListRB) Build() *UpdateTasksListDTO {
 		Name:      task.Name,
 		Color:     task.Color,
 		Hidden:    task.Hidden,
+		FolderID:  task.FolderID,
 		UpdatedAt: time.Now(),
 	}
 }
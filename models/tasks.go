@@ -1,69 +1,213 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"main/utils/rrule"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SubTask struct {
+	ID       string `json:"id" bson:"id"`
+	Title    string `json:"title" bson:"title"`
+	Complete bool   `json:"complete" bson:"complete"`
+	Position int    `json:"position" bson:"position"`
+}
+
+type SubTaskRB struct {
+	ID       string `json:"id" bson:"id" validate:"regexp=^([0-9a-f]{24})?$"`
+	Title    string `json:"title" bson:"title" validate:"nonzero"`
+	Complete bool   `json:"complete" bson:"complete"`
+}
+
+type CreateSubTaskRB struct {
+	Title    string `json:"title" bson:"title" validate:"nonzero"`
+	Complete bool   `json:"complete" bson:"complete"`
+	Position *int   `json:"position" bson:"position"`
+}
+
+type UpdateSubTaskRB struct {
+	Title    *string `json:"title" bson:"title"`
+	Complete *bool   `json:"complete" bson:"complete"`
+	Position *int    `json:"position" bson:"position"`
+}
 
 type Task struct {
-	ID     string `json:"id" bson:"_id,omitempty"`
-	UserID string `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
-	ListID string `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
-	Title  string `json:"title" bson:"title" validate:"nonzero"`
-	Note   string `json:"note" bson:"note"`
-	Subs   []struct {
-		Title    string `json:"title" bson:"title"`
-		Complete bool   `json:"complete" bson:"complete"`
-	} `json:"subs" bson:"subs"`
-	Complete  bool      `json:"complete" bson:"complete" validate:"nonnil"`
-	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
-	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+	ID                   string     `json:"id" bson:"_id,omitempty"`
+	UserID               string     `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	ListID               string     `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
+	Title                string     `json:"title" bson:"title" validate:"nonzero"`
+	Note                 string     `json:"note" bson:"note"`
+	Subs                 []SubTask  `json:"subs" bson:"subs"`
+	Complete             bool       `json:"complete" bson:"complete" validate:"nonnil"`
+	StatusID             string     `json:"status_id" bson:"status_id" validate:"max=24,regexp=^[0-9a-z_]*$"`
+	Priority             Priority   `json:"priority" bson:"priority" validate:"priority"`
+	Starred              bool       `json:"starred" bson:"starred"`
+	Tags                 []string   `json:"tags" bson:"tags"`
+	AssigneeID           string     `json:"assignee_id" bson:"assignee_id" validate:"regexp=^([0-9a-f]{24})?$"`
+	EstimateMinutes      int        `json:"estimate_minutes" bson:"estimate_minutes" validate:"min=0"`
+	Position             string     `json:"position" bson:"position"`
+	StartAt              *time.Time `json:"start_at" bson:"start_at"`
+	DueAt                *time.Time `json:"due_at" bson:"due_at"`
+	DueAllDay            bool       `json:"due_all_day" bson:"due_all_day"`
+	Timezone             string     `json:"timezone" bson:"timezone" validate:"timezone"`
+	RRule                string     `json:"rrule" bson:"rrule" validate:"rrule"`
+	RepeatFromCompletion bool       `json:"repeat_from_completion" bson:"repeat_from_completion"`
+	SeriesID             string     `json:"series_id" bson:"series_id"`
+	Occurrence           int        `json:"occurrence" bson:"occurrence"`
+	CommentsCount        int        `json:"comments_count" bson:"comments_count"`
+	IsBlocked            bool       `json:"is_blocked" bson:"-"`
+	DeletedAt            *time.Time `json:"deleted_at" bson:"deleted_at"`
+	UpdatedAt            time.Time  `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt            time.Time  `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+}
+
+// Progress returns the share of completed subtasks in percent. A task
+// without subtasks is either 0 or 100 percent done.
+func (t Task) Progress() int {
+	if len(t.Subs) == 0 {
+		if t.Complete {
+			return 100
+		}
+		return 0
+	}
+
+	done := 0
+	for _, sub := range t.Subs {
+		if sub.Complete {
+			done++
+		}
+	}
+
+	return int(math.Round(float64(done) * 100 / float64(len(t.Subs))))
 }
 
+// MarshalJSON adds the computed progress and returns subtasks ordered by
+// their position.
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+
+	t.Subs = append(t.Subs[:0:0], t.Subs...)
+	sort.SliceStable(t.Subs, func(i, j int) bool { return t.Subs[i].Position < t.Subs[j].Position })
+
+	return json.Marshal(struct {
+		task
+		Progress int `json:"progress"`
+	}{task(t), t.Progress()})
+}
+
+// CreateTaskRB leaves ListID empty to create the task in the user's Inbox.
 type CreateTaskRB struct {
-	ListID string `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
-	Title  string `json:"title" bson:"title" validate:"nonzero"`
-	Note   string `json:"note" bson:"note"`
-	Subs   []struct {
-		Title    string `json:"title" bson:"title"`
-		Complete bool   `json:"complete" bson:"complete"`
-	} `json:"subs" bson:"subs"`
-	Complete bool `json:"complete" bson:"complete" validate:"nonnil"`
+	ListID               string      `json:"list_id" bson:"list_id" validate:"regexp=^([0-9a-f]{24})?$"`
+	Title                string      `json:"title" bson:"title" validate:"nonzero"`
+	Note                 string      `json:"note" bson:"note"`
+	Subs                 []SubTaskRB `json:"subs" bson:"subs"`
+	Complete             bool        `json:"complete" bson:"complete" validate:"nonnil"`
+	StatusID             string      `json:"status_id" bson:"status_id" validate:"max=24,regexp=^[0-9a-z_]*$"`
+	Priority             Priority    `json:"priority" bson:"priority" validate:"priority"`
+	Starred              bool        `json:"starred" bson:"starred"`
+	Tags                 []string    `json:"tags" bson:"tags"`
+	AssigneeID           string      `json:"assignee_id" bson:"assignee_id" validate:"regexp=^([0-9a-f]{24})?$"`
+	EstimateMinutes      int         `json:"estimate_minutes" bson:"estimate_minutes" validate:"min=0"`
+	StartAt              *time.Time  `json:"start_at" bson:"start_at"`
+	DueAt                *time.Time  `json:"due_at" bson:"due_at"`
+	DueAllDay            bool        `json:"due_all_day" bson:"due_all_day"`
+	Timezone             string      `json:"timezone" bson:"timezone" validate:"timezone"`
+	RRule                string      `json:"rrule" bson:"rrule" validate:"rrule"`
+	RepeatFromCompletion bool        `json:"repeat_from_completion" bson:"repeat_from_completion"`
 }
 
 type CreateTaskDTO struct {
-	UserID string `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
-	ListID string `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
-	Title  string `json:"title" bson:"title" validate:"nonzero"`
-	Note   string `json:"note" bson:"note"`
-	Subs   []struct {
-		Title    string `json:"title" bson:"title"`
-		Complete bool   `json:"complete" bson:"complete"`
-	} `json:"subs" bson:"subs"`
-	Complete  bool      `json:"complete" bson:"complete" validate:"nonnil"`
-	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
-	CreatedAt time.Time `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
+	UserID               string     `json:"user_id" bson:"user_id" validate:"nonzero,len=24"`
+	ListID               string     `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
+	Title                string     `json:"title" bson:"title" validate:"nonzero"`
+	Note                 string     `json:"note" bson:"note"`
+	Subs                 []SubTask  `json:"subs" bson:"subs"`
+	Complete             bool       `json:"complete" bson:"complete" validate:"nonnil"`
+	StatusID             string     `json:"status_id" bson:"status_id" validate:"max=24,regexp=^[0-9a-z_]*$"`
+	Priority             Priority   `json:"priority" bson:"priority" validate:"priority"`
+	Starred              bool       `json:"starred" bson:"starred"`
+	Tags                 []string   `json:"tags" bson:"tags"`
+	AssigneeID           string     `json:"assignee_id" bson:"assignee_id" validate:"regexp=^([0-9a-f]{24})?$"`
+	EstimateMinutes      int        `json:"estimate_minutes" bson:"estimate_minutes" validate:"min=0"`
+	Position             string     `json:"position" bson:"position"`
+	StartAt              *time.Time `json:"start_at" bson:"start_at"`
+	DueAt                *time.Time `json:"due_at" bson:"due_at"`
+	DueAllDay            bool       `json:"due_all_day" bson:"due_all_day"`
+	Timezone             string     `json:"timezone" bson:"timezone" validate:"timezone"`
+	RRule                string     `json:"rrule" bson:"rrule" validate:"rrule"`
+	RepeatFromCompletion bool       `json:"repeat_from_completion" bson:"repeat_from_completion"`
+	SeriesID             string     `json:"series_id" bson:"series_id"`
+	Occurrence           int        `json:"occurrence" bson:"occurrence"`
+	UpdatedAt            time.Time  `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	CreatedAt            time.Time  `json:"CreatedAt" bson:"CreatedAt" validate:"nonzero"`
 }
 
 type UpdateTaskRB struct {
-	ID     string `json:"id" bson:"_id,omitempty"`
-	ListID string `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
-	Title  string `json:"title" bson:"title" validate:"nonzero"`
-	Note   string `json:"note" bson:"note"`
-	Subs   []struct {
-		Title    string `json:"title" bson:"title"`
-		Complete bool   `json:"complete" bson:"complete"`
-	} `json:"subs" bson:"subs"`
-	Complete bool `json:"complete" bson:"complete" validate:"nonnil"`
+	ID                   string      `json:"id" bson:"_id,omitempty"`
+	ListID               string      `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
+	Title                string      `json:"title" bson:"title" validate:"nonzero"`
+	Note                 string      `json:"note" bson:"note"`
+	Subs                 []SubTaskRB `json:"subs" bson:"subs"`
+	Complete             bool        `json:"complete" bson:"complete" validate:"nonnil"`
+	StatusID             string      `json:"status_id" bson:"status_id" validate:"max=24,regexp=^[0-9a-z_]*$"`
+	Priority             Priority    `json:"priority" bson:"priority" validate:"priority"`
+	Starred              bool        `json:"starred" bson:"starred"`
+	Tags                 []string    `json:"tags" bson:"tags"`
+	AssigneeID           string      `json:"assignee_id" bson:"assignee_id" validate:"regexp=^([0-9a-f]{24})?$"`
+	EstimateMinutes      int         `json:"estimate_minutes" bson:"estimate_minutes" validate:"min=0"`
+	StartAt              *time.Time  `json:"start_at" bson:"start_at"`
+	DueAt                *time.Time  `json:"due_at" bson:"due_at"`
+	DueAllDay            bool        `json:"due_all_day" bson:"due_all_day"`
+	Timezone             string      `json:"timezone" bson:"timezone" validate:"timezone"`
+	RRule                string      `json:"rrule" bson:"rrule" validate:"rrule"`
+	RepeatFromCompletion bool        `json:"repeat_from_completion" bson:"repeat_from_completion"`
 }
 
 type UpdateTaskDTO struct {
-	ListID string `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
-	Title  string `json:"title" bson:"title" validate:"nonzero"`
-	Note   string `json:"note" bson:"note"`
-	Subs   []struct {
-		Title    string `json:"title" bson:"title"`
-		Complete bool   `json:"complete" bson:"complete"`
-	} `json:"subs" bson:"subs"`
-	Complete  bool      `json:"complete" bson:"complete" validate:"nonnil"`
-	UpdatedAt time.Time `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+	ListID               string     `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
+	Title                string     `json:"title" bson:"title" validate:"nonzero"`
+	Note                 string     `json:"note" bson:"note"`
+	Subs                 []SubTask  `json:"subs" bson:"subs"`
+	Complete             bool       `json:"complete" bson:"complete" validate:"nonnil"`
+	StatusID             string     `json:"status_id" bson:"status_id" validate:"max=24,regexp=^[0-9a-z_]*$"`
+	Priority             Priority   `json:"priority" bson:"priority" validate:"priority"`
+	Starred              bool       `json:"starred" bson:"starred"`
+	Tags                 []string   `json:"tags" bson:"tags"`
+	AssigneeID           string     `json:"assignee_id" bson:"assignee_id" validate:"regexp=^([0-9a-f]{24})?$"`
+	EstimateMinutes      int        `json:"estimate_minutes" bson:"estimate_minutes" validate:"min=0"`
+	StartAt              *time.Time `json:"start_at" bson:"start_at"`
+	DueAt                *time.Time `json:"due_at" bson:"due_at"`
+	DueAllDay            bool       `json:"due_all_day" bson:"due_all_day"`
+	Timezone             string     `json:"timezone" bson:"timezone" validate:"timezone"`
+	RRule                string     `json:"rrule" bson:"rrule" validate:"rrule"`
+	RepeatFromCompletion bool       `json:"repeat_from_completion" bson:"repeat_from_completion"`
+	UpdatedAt            time.Time  `json:"UpdatedAt" bson:"UpdatedAt" validate:"nonzero"`
+}
+
+// TasksFilter holds the query string options of task listings. Assignee
+// is a user id, "me" or "none" for unassigned tasks.
+type TasksFilter struct {
+	Sort      string   `validate:"regexp=^(|position|priority|due|created)$"`
+	Tags      []string `validate:"max=20"`
+	TagsMatch string   `validate:"regexp=^(|any|all)$"`
+	Assignee  string   `validate:"regexp=^(|me|none|[0-9a-f]{24})$"`
+}
+
+// MoveRB places an item between two neighbours of the same list. After is
+// the item that ends up directly before the moved one, Before the one
+// directly after it. Leaving both empty moves the item to the end.
+type MoveRB struct {
+	After  string `json:"after" validate:"regexp=^([0-9a-f]{24})?$"`
+	Before string `json:"before" validate:"regexp=^([0-9a-f]{24})?$"`
+}
+
+type BulkTasksRB struct {
+	IDs    []string `json:"ids" bson:"ids" validate:"min=1,max=500"`
+	ListID string   `json:"list_id" bson:"list_id" validate:"nonzero,len=24"`
 }
 
 type DeleteTaskDTO struct {
@@ -72,38 +216,272 @@ type DeleteTaskDTO struct {
 
 func (t CreateTaskRB) Build(uid string) *CreateTaskDTO {
 	return &CreateTaskDTO{
-		UserID:    uid,
-		ListID:    t.ListID,
-		Title:     t.Title,
-		Note:      t.Note,
-		Subs:      t.Subs,
-		Complete:  t.Complete,
-		UpdatedAt: time.Now(),
-		CreatedAt: time.Now(),
+		UserID:               uid,
+		ListID:               t.ListID,
+		Title:                t.Title,
+		Note:                 t.Note,
+		Subs:                 buildSubs(t.Subs),
+		Complete:             t.Complete,
+		StatusID:             t.StatusID,
+		Priority:             t.Priority,
+		Starred:              t.Starred,
+		Tags:                 t.Tags,
+		AssigneeID:           t.AssigneeID,
+		EstimateMinutes:      t.EstimateMinutes,
+		StartAt:              normalizeDate(t.StartAt, t.DueAllDay),
+		DueAt:                normalizeDate(t.DueAt, t.DueAllDay),
+		DueAllDay:            t.DueAllDay,
+		Timezone:             t.Timezone,
+		RRule:                t.RRule,
+		RepeatFromCompletion: t.RepeatFromCompletion,
+		UpdatedAt:            time.Now(),
+		CreatedAt:            time.Now(),
 	}
 }
 
 func (t UpdateTaskRB) Build() *UpdateTaskDTO {
 	return &UpdateTaskDTO{
-		ListID:    t.ListID,
-		Title:     t.Title,
-		Note:      t.Note,
-		Subs:      t.Subs,
-		Complete:  t.Complete,
-		UpdatedAt: time.Now(),
+		ListID:               t.ListID,
+		Title:                t.Title,
+		Note:                 t.Note,
+		Subs:                 buildSubs(t.Subs),
+		Complete:             t.Complete,
+		StatusID:             t.StatusID,
+		Priority:             t.Priority,
+		Starred:              t.Starred,
+		Tags:                 t.Tags,
+		AssigneeID:           t.AssigneeID,
+		EstimateMinutes:      t.EstimateMinutes,
+		StartAt:              normalizeDate(t.StartAt, t.DueAllDay),
+		DueAt:                normalizeDate(t.DueAt, t.DueAllDay),
+		DueAllDay:            t.DueAllDay,
+		Timezone:             t.Timezone,
+		RRule:                t.RRule,
+		RepeatFromCompletion: t.RepeatFromCompletion,
+		UpdatedAt:            time.Now(),
 	}
 }
 
 func (t CreateTaskDTO) Build(id string) *Task {
 	return &Task{
-		ID:        id,
-		UserID:    t.UserID,
-		ListID:    t.ListID,
-		Title:     t.Title,
-		Note:      t.Note,
-		Subs:      t.Subs,
-		Complete:  t.Complete,
-		UpdatedAt: t.UpdatedAt,
-		CreatedAt: t.CreatedAt,
+		ID:                   id,
+		UserID:               t.UserID,
+		ListID:               t.ListID,
+		Title:                t.Title,
+		Note:                 t.Note,
+		Subs:                 t.Subs,
+		Complete:             t.Complete,
+		StatusID:             t.StatusID,
+		Priority:             t.Priority,
+		Starred:              t.Starred,
+		Tags:                 t.Tags,
+		AssigneeID:           t.AssigneeID,
+		EstimateMinutes:      t.EstimateMinutes,
+		StartAt:              t.StartAt,
+		DueAt:                t.DueAt,
+		DueAllDay:            t.DueAllDay,
+		Timezone:             t.Timezone,
+		RRule:                t.RRule,
+		RepeatFromCompletion: t.RepeatFromCompletion,
+		SeriesID:             t.SeriesID,
+		Occurrence:           t.Occurrence,
+		Position:             t.Position,
+		UpdatedAt:            t.UpdatedAt,
+		CreatedAt:            t.CreatedAt,
+	}
+}
+
+// buildSubs assigns ids and positions to subtasks. A missing list is an
+// empty one, so updates without subtasks clear them.
+func buildSubs(subs []SubTaskRB) []SubTask {
+	result := make([]SubTask, len(subs))
+	for i, sub := range subs {
+		id := sub.ID
+		if id == "" {
+			id = primitive.NewObjectID().Hex()
+		}
+
+		result[i] = SubTask{
+			ID:       id,
+			Title:    sub.Title,
+			Complete: sub.Complete,
+			Position: i,
+		}
+	}
+
+	return result
+}
+
+func (s CreateSubTaskRB) Build(position int) SubTask {
+	if s.Position != nil {
+		position = *s.Position
+	}
+
+	return SubTask{
+		ID:       primitive.NewObjectID().Hex(),
+		Title:    s.Title,
+		Complete: s.Complete,
+		Position: position,
+	}
+}
+
+// normalizeDate keeps all-day dates as the calendar day they were sent for,
+// see DateOf, and other dates in UTC.
+func normalizeDate(date *time.Time, allDay bool) *time.Time {
+	if date == nil {
+		return nil
+	}
+
+	if !allDay {
+		utc := date.UTC()
+		return &utc
 	}
+
+	day := DateOf(*date)
+
+	return &day
+}
+
+// DueInstant returns when the task is due. All-day tasks are due at the
+// start of their date in the task timezone.
+func (t Task) DueInstant() *time.Time {
+	if t.DueAt == nil || !t.DueAllDay {
+		return t.DueAt
+	}
+
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	due := time.Date(t.DueAt.Year(), t.DueAt.Month(), t.DueAt.Day(), 0, 0, 0, 0, loc).UTC()
+
+	return &due
+}
+
+// DateOf returns the calendar day t falls on in its location as midnight
+// UTC, the form all-day dates are stored in. All-day tasks are due on a
+// date rather than an instant, so they are compared with the DateOf the
+// day boundaries of a request, whatever its timezone.
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Copy builds a new task with the same content in another list. The copy
+// starts a recurrence series of its own, is left unassigned and is put in
+// the status of the list that matches its completion.
+func (t Task) Copy(listID string) *CreateTaskDTO {
+	return &CreateTaskDTO{
+		UserID:               t.UserID,
+		ListID:               listID,
+		Title:                t.Title,
+		Note:                 t.Note,
+		Subs:                 t.Subs,
+		Complete:             t.Complete,
+		Priority:             t.Priority,
+		Starred:              t.Starred,
+		Tags:                 t.Tags,
+		EstimateMinutes:      t.EstimateMinutes,
+		StartAt:              t.StartAt,
+		DueAt:                t.DueAt,
+		DueAllDay:            t.DueAllDay,
+		Timezone:             t.Timezone,
+		RRule:                t.RRule,
+		RepeatFromCompletion: t.RepeatFromCompletion,
+		UpdatedAt:            time.Now(),
+		CreatedAt:            time.Now(),
+	}
+}
+
+// NextOccurrence builds the task that follows t in its recurrence series.
+// ok is false when t does not repeat or the series has ended.
+func (t Task) NextOccurrence(completedAt time.Time) (next *CreateTaskDTO, ok bool, err error) {
+	if t.RRule == "" {
+		return nil, false, nil
+	}
+
+	rule, err := rrule.Parse(t.RRule)
+	if err != nil {
+		return nil, false, err
+	}
+
+	occurrence := t.Occurrence
+	if occurrence == 0 {
+		occurrence = 1
+	}
+
+	if rule.Count > 0 && occurrence >= rule.Count {
+		return nil, false, nil
+	}
+
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	// All-day dates step through the days of UTC they are stored in, but
+	// are completed on a day of the task timezone.
+	dateLoc := loc
+	if t.DueAllDay {
+		dateLoc = time.UTC
+	}
+
+	var due time.Time
+	if t.RepeatFromCompletion || t.DueAt == nil {
+		base := completedAt.In(loc)
+		if t.DueAt != nil {
+			h, m, s := t.DueAt.In(dateLoc).Clock()
+			base = time.Date(base.Year(), base.Month(), base.Day(), h, m, s, 0, dateLoc)
+		}
+		due = rule.Step(base)
+		if rule.PastUntil(due) {
+			return nil, false, nil
+		}
+	} else {
+		start := t.DueAt.In(dateLoc)
+		due, _, ok = rule.Next(start, start)
+		if !ok {
+			return nil, false, nil
+		}
+	}
+
+	seriesID := t.SeriesID
+	if seriesID == "" {
+		seriesID = t.ID
+	}
+
+	due = due.UTC()
+	next = &CreateTaskDTO{
+		UserID:               t.UserID,
+		ListID:               t.ListID,
+		Title:                t.Title,
+		Note:                 t.Note,
+		Subs:                 append(t.Subs[:0:0], t.Subs...),
+		Complete:             false,
+		Priority:             t.Priority,
+		Starred:              t.Starred,
+		Tags:                 t.Tags,
+		AssigneeID:           t.AssigneeID,
+		EstimateMinutes:      t.EstimateMinutes,
+		DueAt:                &due,
+		DueAllDay:            t.DueAllDay,
+		Timezone:             t.Timezone,
+		RRule:                t.RRule,
+		RepeatFromCompletion: t.RepeatFromCompletion,
+		SeriesID:             seriesID,
+		Occurrence:           occurrence + 1,
+		UpdatedAt:            time.Now(),
+		CreatedAt:            time.Now(),
+	}
+
+	if t.StartAt != nil && t.DueAt != nil {
+		start := due.Add(t.StartAt.Sub(*t.DueAt))
+		next.StartAt = &start
+	}
+
+	for i := range next.Subs {
+		next.Subs[i].Complete = false
+	}
+
+	return next, true, nil
 }
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func at(y int, m time.Month, d int, h int) *time.Time {
+	t := time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestNextOccurrence(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        Task
+		completedAt time.Time
+		wantDue     *time.Time
+		wantStart   *time.Time
+		wantN       int
+	}{
+		{
+			name:        "does not repeat",
+			task:        Task{ID: "t1", DueAt: at(2024, time.January, 1, 9)},
+			completedAt: *at(2024, time.January, 1, 10),
+		},
+		{
+			name:        "repeats from due",
+			task:        Task{ID: "t1", RRule: "FREQ=WEEKLY", DueAt: at(2024, time.January, 1, 9)},
+			completedAt: *at(2024, time.January, 3, 15),
+			wantDue:     at(2024, time.January, 8, 9),
+			wantN:       2,
+		},
+		{
+			name:        "repeats from due when completed late",
+			task:        Task{ID: "t1", RRule: "FREQ=DAILY", DueAt: at(2024, time.January, 1, 9), Occurrence: 4},
+			completedAt: *at(2024, time.January, 10, 15),
+			wantDue:     at(2024, time.January, 2, 9),
+			wantN:       5,
+		},
+		{
+			name:        "repeats from completion",
+			task:        Task{ID: "t1", RRule: "FREQ=DAILY;INTERVAL=2", RepeatFromCompletion: true, DueAt: at(2024, time.January, 1, 9)},
+			completedAt: *at(2024, time.January, 5, 15),
+			wantDue:     at(2024, time.January, 7, 9),
+			wantN:       2,
+		},
+		{
+			name:        "repeats from completion without due",
+			task:        Task{ID: "t1", RRule: "FREQ=WEEKLY"},
+			completedAt: *at(2024, time.January, 5, 15),
+			wantDue:     at(2024, time.January, 12, 15),
+			wantN:       2,
+		},
+		{
+			name:        "keeps the distance between start and due",
+			task:        Task{ID: "t1", RRule: "FREQ=DAILY", StartAt: at(2024, time.January, 1, 8), DueAt: at(2024, time.January, 1, 9)},
+			completedAt: *at(2024, time.January, 1, 9),
+			wantDue:     at(2024, time.January, 2, 9),
+			wantStart:   at(2024, time.January, 2, 8),
+			wantN:       2,
+		},
+		{
+			name:        "series exhausted by COUNT",
+			task:        Task{ID: "t1", RRule: "FREQ=DAILY;COUNT=3", DueAt: at(2024, time.January, 3, 9), Occurrence: 3},
+			completedAt: *at(2024, time.January, 3, 10),
+		},
+		{
+			name:        "series exhausted by UNTIL",
+			task:        Task{ID: "t1", RRule: "FREQ=DAILY;UNTIL=20240103", DueAt: at(2024, time.January, 3, 9)},
+			completedAt: *at(2024, time.January, 3, 10),
+		},
+		{
+			name:        "series repeating from completion exhausted by UNTIL",
+			task:        Task{ID: "t1", RRule: "FREQ=DAILY;UNTIL=20240103", RepeatFromCompletion: true, DueAt: at(2024, time.January, 1, 9)},
+			completedAt: *at(2024, time.January, 3, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok, err := tt.task.NextOccurrence(tt.completedAt)
+			if err != nil {
+				t.Fatalf("NextOccurrence() failed: %s", err)
+			}
+
+			if tt.wantDue == nil {
+				if ok {
+					t.Fatalf("NextOccurrence() = due %v, want the series to end", next.DueAt)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("NextOccurrence() ended the series, want due %v", tt.wantDue)
+			}
+			if !next.DueAt.Equal(*tt.wantDue) {
+				t.Errorf("due = %v, want %v", next.DueAt, tt.wantDue)
+			}
+			if (next.StartAt == nil) != (tt.wantStart == nil) || (tt.wantStart != nil && !next.StartAt.Equal(*tt.wantStart)) {
+				t.Errorf("start = %v, want %v", next.StartAt, tt.wantStart)
+			}
+			if next.Occurrence != tt.wantN {
+				t.Errorf("occurrence = %d, want %d", next.Occurrence, tt.wantN)
+			}
+			if next.SeriesID != "t1" {
+				t.Errorf("series = %q, want %q", next.SeriesID, "t1")
+			}
+			if next.Complete {
+				t.Errorf("next occurrence is complete")
+			}
+		})
+	}
+}
+
+func TestNextOccurrenceResetsSubtasks(t *testing.T) {
+	task := Task{
+		ID:    "t1",
+		RRule: "FREQ=DAILY",
+		DueAt: at(2024, time.January, 1, 9),
+		Subs:  []SubTask{{ID: "s1", Title: "sub", Complete: true}},
+	}
+
+	next, ok, err := task.NextOccurrence(*at(2024, time.January, 1, 10))
+	if err != nil || !ok {
+		t.Fatalf("NextOccurrence() = %t, %v", ok, err)
+	}
+
+	if len(next.Subs) != 1 || next.Subs[0].Complete {
+		t.Errorf("subs = %+v, want one open subtask", next.Subs)
+	}
+	if !task.Subs[0].Complete {
+		t.Errorf("NextOccurrence() changed the subtasks of the completed task")
+	}
+}
+
+// TestAllDayDueDates compares all-day due dates with the bounds the views
+// and list stats build from the start of a request day.
+func TestAllDayDueDates(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		due          time.Time
+		timezone     string
+		now          time.Time
+		wantOverdue  bool
+		wantToday    bool
+		wantUpcoming bool
+	}{
+		{"UTC task, LA evening of its day", time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), "", time.Date(2024, time.May, 10, 20, 0, 0, 0, la), false, true, false},
+		{"UTC task, LA morning of its day", time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), "", time.Date(2024, time.May, 10, 1, 0, 0, 0, la), false, true, false},
+		{"UTC task, LA day before", time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), "", time.Date(2024, time.May, 9, 18, 0, 0, 0, la), false, false, true},
+		{"UTC task, LA day after", time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), "", time.Date(2024, time.May, 11, 8, 0, 0, 0, la), true, false, false},
+		{"Tokyo task, LA evening of its day", time.Date(2024, time.May, 10, 0, 0, 0, 0, tokyo), "Asia/Tokyo", time.Date(2024, time.May, 10, 20, 0, 0, 0, la), false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := CreateTaskRB{DueAt: &tt.due, DueAllDay: true, Timezone: tt.timezone}.Build("u1")
+
+			today := time.Date(tt.now.Year(), tt.now.Month(), tt.now.Day(), 0, 0, 0, 0, tt.now.Location())
+			date, tomorrow, later := DateOf(today), DateOf(today.AddDate(0, 0, 1)), DateOf(today.AddDate(0, 0, 8))
+			due := *task.DueAt
+
+			if overdue := due.Before(date); overdue != tt.wantOverdue {
+				t.Errorf("overdue = %t, want %t", overdue, tt.wantOverdue)
+			}
+			if dueToday := !due.Before(date) && due.Before(tomorrow); dueToday != tt.wantToday {
+				t.Errorf("due today = %t, want %t", dueToday, tt.wantToday)
+			}
+			if upcoming := !due.Before(tomorrow) && due.Before(later); upcoming != tt.wantUpcoming {
+				t.Errorf("upcoming = %t, want %t", upcoming, tt.wantUpcoming)
+			}
+		})
+	}
+}
+
+func TestDueInstant(t *testing.T) {
+	due := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+	task := Task{DueAt: &due, DueAllDay: true, Timezone: "America/Los_Angeles"}
+
+	want := time.Date(2024, time.May, 10, 7, 0, 0, 0, time.UTC)
+	if got := task.DueInstant(); !got.Equal(want) {
+		t.Errorf("DueInstant() = %v, want %v", got, want)
+	}
+}
+
+func TestNextOccurrenceAllDay(t *testing.T) {
+	due := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+	la, _ := time.LoadLocation("America/Los_Angeles")
+
+	for _, fromCompletion := range []bool{false, true} {
+		task := Task{ID: "t1", RRule: "FREQ=DAILY", DueAt: &due, DueAllDay: true, Timezone: "America/Los_Angeles", RepeatFromCompletion: fromCompletion}
+
+		next, ok, err := task.NextOccurrence(time.Date(2024, time.May, 10, 22, 0, 0, 0, la))
+		if err != nil || !ok {
+			t.Fatalf("NextOccurrence() = %t, %v", ok, err)
+		}
+
+		want := time.Date(2024, time.May, 11, 0, 0, 0, 0, time.UTC)
+		if !next.DueAt.Equal(want) {
+			t.Errorf("repeat from completion %t: due = %v, want %v", fromCompletion, next.DueAt, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	TimeReportByList = "list"
+	TimeReportByTag  = "tag"
+	TimeReportByDay  = "day"
+
+	maxTimeReportDays = 366
+)
+
+// TimeEntry is a span of time a user worked on a task. A running timer is
+// an entry without StoppedAt. ListID and Tags are those of the task when the
+// timer was started, so reports keep the time where it was worked.
+type TimeEntry struct {
+	ID        string     `json:"id" bson:"_id,omitempty"`
+	UserID    string     `json:"user_id" bson:"user_id"`
+	TaskID    string     `json:"task_id" bson:"task_id"`
+	ListID    string     `json:"list_id" bson:"list_id"`
+	Tags      []string   `json:"tags" bson:"tags"`
+	StartedAt time.Time  `json:"started_at" bson:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at" bson:"stopped_at"`
+	Seconds   int64      `json:"seconds" bson:"seconds"`
+	CreatedAt time.Time  `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// Stop ends the entry at stoppedAt.
+func (e *TimeEntry) Stop(stoppedAt time.Time) {
+	e.StoppedAt = &stoppedAt
+	e.Seconds = int64(stoppedAt.Sub(e.StartedAt).Seconds())
+	if e.Seconds < 0 {
+		e.Seconds = 0
+	}
+}
+
+// NewTimeEntry starts an entry of uid on task with the given id.
+func NewTimeEntry(id string, uid string, task Task, startedAt time.Time) *TimeEntry {
+	tags := task.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &TimeEntry{
+		ID:        id,
+		UserID:    uid,
+		TaskID:    task.ID,
+		ListID:    task.ListID,
+		Tags:      tags,
+		StartedAt: startedAt,
+		CreatedAt: startedAt,
+	}
+}
+
+// TimeReportQuery selects the entries started between From and To, both
+// days in Location, and how they are grouped.
+type TimeReportQuery struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+	Location *time.Location
+}
+
+// ParseTimeReportQuery reads the query string of a time report. from and to
+// are inclusive days as YYYY-MM-DD in the timezone tz, UTC by default. The
+// entries are grouped by list unless groupBy says otherwise.
+func ParseTimeReportQuery(from string, to string, groupBy string, tz string) (query TimeReportQuery, err error) {
+	query.Location, err = time.LoadLocation(tz)
+	if err != nil {
+		return query, err
+	}
+
+	query.From, err = time.ParseInLocation("2006-01-02", from, query.Location)
+	if err != nil {
+		return query, fmt.Errorf("from must be a date as YYYY-MM-DD")
+	}
+
+	query.To, err = time.ParseInLocation("2006-01-02", to, query.Location)
+	if err != nil {
+		return query, fmt.Errorf("to must be a date as YYYY-MM-DD")
+	}
+
+	if query.To.Before(query.From) {
+		return query, fmt.Errorf("to must not be before from")
+	}
+
+	if query.To.Sub(query.From) >= maxTimeReportDays*24*time.Hour {
+		return query, fmt.Errorf("reports can span at most %d days", maxTimeReportDays)
+	}
+
+	switch groupBy {
+	case "":
+		query.GroupBy = TimeReportByList
+	case TimeReportByList, TimeReportByTag, TimeReportByDay:
+		query.GroupBy = groupBy
+	default:
+		return query, fmt.Errorf("group_by must be list, tag or day")
+	}
+
+	return query, nil
+}
+
+// End returns the start of the day after To.
+func (q TimeReportQuery) End() time.Time {
+	return q.To.AddDate(0, 0, 1)
+}
+
+// TimeReport sums the tracked time of a user per group. An entry with
+// several tags counts for each of them, so groups by tag can add up to
+// more than TotalSeconds. Entries are counted on the day they started.
+type TimeReport struct {
+	From         string            `json:"from"`
+	To           string            `json:"to"`
+	GroupBy      string            `json:"group_by"`
+	TotalSeconds int64             `json:"total_seconds"`
+	Groups       []TimeReportGroup `json:"groups"`
+}
+
+// TimeReportGroup is the tracked time of one list, tag or day. Key is empty
+// for the entries of untagged tasks.
+type TimeReportGroup struct {
+	Key     string `json:"key" bson:"_id"`
+	Name    string `json:"name" bson:"-"`
+	Seconds int64  `json:"seconds" bson:"seconds"`
+	Entries int64  `json:"entries" bson:"entries"`
+}
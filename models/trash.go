@@ -0,0 +1,6 @@
+package models
+
+type Trash struct {
+	Tasks      []Task      `json:"tasks"`
+	TasksLists []TasksList `json:"tasks_lists"`
+}
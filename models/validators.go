@@ -0,0 +1,65 @@
+package models
+
+import (
+	"main/utils/rrule"
+	"reflect"
+	"time"
+
+	"gopkg.in/validator.v2"
+)
+
+func init() {
+	validator.SetValidationFunc("timezone", validateTimezone)
+	validator.SetValidationFunc("rrule", validateRRule)
+	validator.SetValidationFunc("priority", validatePriority)
+}
+
+// validateTimezone accepts an empty string or any IANA timezone name.
+func validateTimezone(v interface{}, param string) error {
+	st := reflect.ValueOf(v)
+	if st.Kind() != reflect.String {
+		return validator.ErrUnsupported
+	}
+
+	if st.String() == "" {
+		return nil
+	}
+
+	if _, err := time.LoadLocation(st.String()); err != nil {
+		return validator.ErrInvalid
+	}
+
+	return nil
+}
+
+// validateRRule accepts an empty string or a recurrence rule supported by
+// the rrule package.
+func validateRRule(v interface{}, param string) error {
+	st := reflect.ValueOf(v)
+	if st.Kind() != reflect.String {
+		return validator.ErrUnsupported
+	}
+
+	if st.String() == "" {
+		return nil
+	}
+
+	if _, err := rrule.Parse(st.String()); err != nil {
+		return validator.ErrInvalid
+	}
+
+	return nil
+}
+
+func validatePriority(v interface{}, param string) error {
+	p, ok := v.(Priority)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if p < PriorityNone || p > PriorityUrgent {
+		return validator.ErrInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status categories. Tasks in a status of the done category are complete,
+// tasks in any other status are not.
+const (
+	CategoryTodo       = "todo"
+	CategoryInProgress = "in_progress"
+	CategoryDone       = "done"
+)
+
+// defaultWorkflow is the workflow of lists that did not configure one.
+var defaultWorkflow = []WorkflowStatus{
+	{ID: "todo", Name: "To do", Category: CategoryTodo},
+	{ID: "in_progress", Name: "In progress", Category: CategoryInProgress},
+	{ID: "done", Name: "Done", Category: CategoryDone},
+}
+
+// WorkflowStatus is a column of the board of a list.
+type WorkflowStatus struct {
+	ID       string `json:"id" bson:"id"`
+	Name     string `json:"name" bson:"name"`
+	Category string `json:"category" bson:"category"`
+}
+
+// Done reports whether tasks in the status are complete.
+func (s WorkflowStatus) Done() bool {
+	return s.Category == CategoryDone
+}
+
+type WorkflowStatusRB struct {
+	ID       string `json:"id" validate:"max=24,regexp=^[0-9a-z_]*$"`
+	Name     string `json:"name" validate:"nonzero,max=64"`
+	Category string `json:"category" validate:"regexp=^(todo|in_progress|done)$"`
+}
+
+// UpdateStatusesRB replaces the workflow of a list, in board order.
+// Statuses without an id are new.
+type UpdateStatusesRB struct {
+	Statuses []WorkflowStatusRB `json:"statuses" validate:"min=1,max=20"`
+}
+
+// Build assigns ids to new statuses and checks that the workflow has a
+// done status and one that is not.
+func (r UpdateStatusesRB) Build() ([]WorkflowStatus, error) {
+	statuses := make([]WorkflowStatus, len(r.Statuses))
+	seen := map[string]bool{}
+	hasDone, hasOpen := false, false
+
+	for i, status := range r.Statuses {
+		id := status.ID
+		if id == "" {
+			id = primitive.NewObjectID().Hex()
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("status %s is listed twice", id)
+		}
+		seen[id] = true
+
+		statuses[i] = WorkflowStatus{ID: id, Name: status.Name, Category: status.Category}
+		if statuses[i].Done() {
+			hasDone = true
+		} else {
+			hasOpen = true
+		}
+	}
+
+	if !hasDone || !hasOpen {
+		return nil, fmt.Errorf("a workflow needs a done status and one that is not done")
+	}
+
+	return statuses, nil
+}
+
+// Workflow returns the statuses of the list in board order.
+func (l TasksList) Workflow() []WorkflowStatus {
+	if len(l.Statuses) == 0 {
+		return defaultWorkflow
+	}
+
+	return l.Statuses
+}
+
+func (l TasksList) status(id string) (WorkflowStatus, bool) {
+	for _, status := range l.Workflow() {
+		if status.ID == id {
+			return status, true
+		}
+	}
+
+	return WorkflowStatus{}, false
+}
+
+// firstStatus returns the first status that is done or the first that is
+// not.
+func (l TasksList) firstStatus(done bool) WorkflowStatus {
+	for _, status := range l.Workflow() {
+		if status.Done() == done {
+			return status
+		}
+	}
+
+	return WorkflowStatus{}
+}
+
+// StatusOf returns the column of a task. Tasks without a status of the
+// list, like those created before workflows existed, are put in the first
+// status matching their completion.
+func (l TasksList) StatusOf(task Task) WorkflowStatus {
+	if status, ok := l.status(task.StatusID); ok && status.Done() == task.Complete {
+		return status
+	}
+
+	return l.firstStatus(task.Complete)
+}
+
+// ApplyStatus reconciles the status and the completion of a task in the
+// list. When statusChanged the status decides whether the task is complete
+// and has to exist. Otherwise completion decides and the status is kept as
+// long as it agrees.
+func (l TasksList) ApplyStatus(statusID string, complete bool, statusChanged bool) (string, bool, error) {
+	if statusChanged && statusID != "" {
+		status, ok := l.status(statusID)
+		if !ok {
+			return "", false, fmt.Errorf("status %s not found in tasks list", statusID)
+		}
+		return status.ID, status.Done(), nil
+	}
+
+	return l.StatusOf(Task{StatusID: statusID, Complete: complete}).ID, complete, nil
+}
+
+// StaleStatuses returns the statuses of before that are gone from after or
+// moved between done and not done. Tasks in them fall back to StatusOf.
+func StaleStatuses(before, after []WorkflowStatus) []string {
+	kept := map[string]bool{}
+	for _, status := range after {
+		kept[status.ID] = status.Done()
+	}
+
+	stale := []string{}
+	for _, status := range before {
+		if done, ok := kept[status.ID]; !ok || done != status.Done() {
+			stale = append(stale, status.ID)
+		}
+	}
+
+	return stale
+}
+
+// Board is a list with its tasks grouped by status.
+type Board struct {
+	ListID  string        `json:"list_id"`
+	Columns []BoardColumn `json:"columns"`
+}
+
+type BoardColumn struct {
+	Status WorkflowStatus `json:"status"`
+	Tasks  []Task         `json:"tasks"`
+}
+
+// BuildBoard groups tasks, in their order, into the columns of the list.
+func BuildBoard(list TasksList, tasks []Task) Board {
+	workflow := list.Workflow()
+	board := Board{ListID: list.ID, Columns: make([]BoardColumn, len(workflow))}
+
+	columns := map[string]int{}
+	for i, status := range workflow {
+		board.Columns[i] = BoardColumn{Status: status, Tasks: []Task{}}
+		columns[status.ID] = i
+	}
+
+	for _, task := range tasks {
+		task.StatusID = list.StatusOf(task).ID
+		i := columns[task.StatusID]
+		board.Columns[i].Tasks = append(board.Columns[i].Tasks, task)
+	}
+
+	return board
+}
@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestStatusOf(t *testing.T) {
+	list := TasksList{Statuses: []WorkflowStatus{
+		{ID: "backlog", Category: CategoryTodo},
+		{ID: "review", Category: CategoryInProgress},
+		{ID: "shipped", Category: CategoryDone},
+	}}
+
+	tests := []struct {
+		name string
+		task Task
+		want string
+	}{
+		{"kept", Task{StatusID: "review"}, "review"},
+		{"legacy open", Task{}, "backlog"},
+		{"legacy complete", Task{Complete: true}, "shipped"},
+		{"status of another list", Task{StatusID: "in_progress"}, "backlog"},
+		{"disagrees with completion", Task{StatusID: "review", Complete: true}, "shipped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list.StatusOf(tt.task).ID; got != tt.want {
+				t.Errorf("StatusOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildBoardNormalisesStatuses(t *testing.T) {
+	board := BuildBoard(TasksList{ID: "l1"}, []Task{
+		{ID: "t1"},
+		{ID: "t2", StatusID: "gone", Complete: true},
+		{ID: "t3", StatusID: "in_progress"},
+	})
+
+	want := map[string]string{"t1": "todo", "t2": "done", "t3": "in_progress"}
+
+	got := map[string]string{}
+	for _, column := range board.Columns {
+		for _, task := range column.Tasks {
+			if task.StatusID != column.Status.ID {
+				t.Errorf("task %s in column %s has status %q", task.ID, column.Status.ID, task.StatusID)
+			}
+			got[task.ID] = column.Status.ID
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("board holds %d tasks, want %d", len(got), len(want))
+	}
+
+	for id, status := range want {
+		if got[id] != status {
+			t.Errorf("task %s in column %q, want %q", id, got[id], status)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type ActivityHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewActivityHandler(router *Router) *ActivityHandler {
+	return &ActivityHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h ActivityHandler) RegisterActivityRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tasks/:id/history", h.middlewares.ApplyMiddlewares(
+		h.GetTaskHistory,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/activity", h.middlewares.ApplyMiddlewares(
+		h.GetActivity,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h ActivityHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	page, err := models.ParsePage(r.URL.Query().Get("limit"), r.URL.Query().Get("offset"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	activity, err := h.Services.GetTaskHistory(context.Background(), h.Parent.param(r, "id"), user.ID, page)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task history: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	activityBytes, _ := json.Marshal(activity)
+
+	h.Parent.send(w, string(activityBytes), http.StatusOK)
+}
+
+func (h ActivityHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	page, err := models.ParsePage(r.URL.Query().Get("limit"), r.URL.Query().Get("offset"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.ActivityFilter{
+		ListID:  r.URL.Query().Get("list_id"),
+		ActorID: r.URL.Query().Get("actor_id"),
+	}
+
+	if err := validator.Validate(filter); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	activity, err := h.Services.GetActivityFeed(context.Background(), user.ID, filter, page)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find activity: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	activityBytes, _ := json.Marshal(activity)
+
+	h.Parent.send(w, string(activityBytes), http.StatusOK)
+}
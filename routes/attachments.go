@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/services"
+	"main/utils/logging"
+	"mime"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// multipartOverhead is allowed on top of the file for the boundaries and
+	// headers of the multipart body.
+	multipartOverhead = 64 << 10
+	// multipartMemory is how much of an upload is kept in memory, the rest
+	// is spooled to a temporary file.
+	multipartMemory = 1 << 20
+)
+
+type AttachmentsHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewAttachmentsHandler(router *Router) *AttachmentsHandler {
+	return &AttachmentsHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h AttachmentsHandler) RegisterAttachmentsRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tasks/:id/attachments", h.middlewares.ApplyMiddlewares(
+		h.GetAllAttachments,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/attachments", h.middlewares.ApplyMiddlewares(
+		h.UploadAttachment,
+		h.middlewares.ForAuth,
+	))
+	// Downloads honour Range headers, so large files can be resumed.
+	h.Router.HandlerFunc(http.MethodGet, "/tasks/:id/attachments/:attachmentId", h.middlewares.ApplyMiddlewares(
+		h.DownloadAttachment,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks/:id/attachments/:attachmentId", h.middlewares.ApplyMiddlewares(
+		h.DeleteAttachment,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h AttachmentsHandler) GetAllAttachments(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	attachments, err := h.Services.GetAttachments(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find attachments: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	attachmentsBytes, _ := json.Marshal(attachments)
+
+	h.Parent.send(w, string(attachmentsBytes), http.StatusOK)
+}
+
+// UploadAttachment expects a multipart/form-data body with the file in the
+// "file" field.
+func (h AttachmentsHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	var maxBytesErr *http.MaxBytesError
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.Services.Attachments.MaxSize()+multipartOverhead)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		if errors.As(err, &maxBytesErr) {
+			h.Parent.error(w, "attachment is too large", http.StatusRequestEntityTooLarge)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	attachment, err := h.Services.AddAttachment(context.Background(), h.Parent.param(r, "id"), user.ID, header.Filename, header.Size, file)
+	if err == services.ErrAttachmentTooLarge {
+		h.Parent.error(w, "attachment is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err == services.ErrAttachmentType {
+		h.Parent.error(w, "attachment type is not allowed", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add attachment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	attachmentBytes, _ := json.Marshal(attachment)
+
+	h.Parent.send(w, string(attachmentBytes), http.StatusOK)
+}
+
+func (h AttachmentsHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	attachment, blob, err := h.Services.OpenAttachment(r.Context(), h.Parent.param(r, "id"), h.Parent.param(r, "attachmentId"), user.ID)
+	if errors.Is(err, services.ErrAttachmentNotFound) {
+		h.Parent.error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find attachment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, attachment.Name, attachment.CreatedAt, blob)
+}
+
+func (h AttachmentsHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	aid, err := h.Services.DeleteAttachment(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "attachmentId"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete attachment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", aid), http.StatusOK)
+}
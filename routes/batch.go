@@ -0,0 +1,320 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type BatchHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewBatchHandler(router *Router) *BatchHandler {
+	return &BatchHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h BatchHandler) RegisterBatchRoutes() {
+	h.Router.HandlerFunc(http.MethodPost, "/batch", h.middlewares.ApplyMiddlewares(
+		h.RunBatch,
+		h.middlewares.ForAuth,
+	))
+}
+
+// batch holds the state of one batch request while its operations run.
+type batch struct {
+	h       BatchHandler
+	uid     string
+	tempIDs map[string]string
+}
+
+func (h BatchHandler) RunBatch(w http.ResponseWriter, r *http.Request) {
+	var BatchRB models.BatchRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&BatchRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(BatchRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	for i, op := range BatchRB.Operations {
+		if err := validator.Validate(op); err != nil {
+			h.Parent.error(w, fmt.Sprintf("validataion error: operation %d: %s", i, err.Error()), http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	b := &batch{h: h, uid: user.ID}
+
+	var results []models.BatchResult
+
+	if BatchRB.Atomic {
+		err = h.Services.Transaction(context.Background(), func(ctx context.Context) error {
+			results = b.run(ctx, BatchRB.Operations, true)
+			for i, result := range results {
+				if !result.Success {
+					return fmt.Errorf("operation %d failed: %s", i, result.Error)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			resultsBytes, _ := json.Marshal(models.RollBack(results, BatchRB.Operations))
+
+			h.Parent.errorWithResult(w, fmt.Sprintf("batch rolled back: %s", err.Error()), string(resultsBytes), http.StatusBadRequest)
+			return
+		}
+	} else {
+		results = b.run(context.Background(), BatchRB.Operations, false)
+	}
+
+	resultsBytes, _ := json.Marshal(results)
+
+	h.Parent.send(w, string(resultsBytes), http.StatusOK)
+}
+
+// run applies the operations in order. In atomic mode it stops at the first
+// failure, otherwise every operation is attempted.
+func (b *batch) run(ctx context.Context, operations []models.BatchOperation, atomic bool) []models.BatchResult {
+	b.tempIDs = map[string]string{}
+	results := make([]models.BatchResult, 0, len(operations))
+
+	for _, op := range operations {
+		result := models.BatchResult{TempID: op.TempID}
+
+		id, value, err := b.apply(ctx, op)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			if atomic {
+				break
+			}
+			continue
+		}
+
+		if op.TempID != "" && op.Op == "create" {
+			b.tempIDs[op.TempID] = id
+		}
+
+		result.ID = id
+		result.Success = true
+		result.Result = value
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func (b *batch) apply(ctx context.Context, op models.BatchOperation) (string, interface{}, error) {
+	switch op.Type + "." + op.Op {
+	case "task.create":
+		var rb models.CreateTaskRB
+		if err := b.decode(op, &rb); err != nil {
+			return "", nil, err
+		}
+
+		listID, err := b.resolve(rb.ListID)
+		if err != nil {
+			return "", nil, err
+		}
+		rb.ListID = listID
+
+		if err := validator.Validate(rb); err != nil {
+			return "", nil, fmt.Errorf("validataion error: %s", err.Error())
+		}
+
+		task, err := b.h.Services.CreateTask(ctx, rb.Build(b.uid))
+		if err != nil {
+			return "", nil, fmt.Errorf("can not add task: %s", err.Error())
+		}
+
+		return task.ID, task, nil
+	case "task.update":
+		var rb models.UpdateTaskRB
+		if err := b.decode(op, &rb); err != nil {
+			return "", nil, err
+		}
+
+		id, err := b.resolve(op.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		listID, err := b.resolve(rb.ListID)
+		if err != nil {
+			return "", nil, err
+		}
+		rb.ID, rb.ListID = id, listID
+
+		if err := validator.Validate(rb); err != nil {
+			return "", nil, fmt.Errorf("validataion error: %s", err.Error())
+		}
+
+		task, err := b.h.Services.UpdateTask(ctx, id, b.uid, rb.Build())
+		if err != nil {
+			return "", nil, fmt.Errorf("can not update task: %s", err.Error())
+		}
+
+		return id, task, nil
+	case "task.delete":
+		id, err := b.resolve(op.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		tid, err := b.h.Services.DeleteTask(ctx, id, b.uid)
+		if err != nil {
+			return "", nil, fmt.Errorf("can not delete task: %s", err.Error())
+		}
+
+		return tid, nil, nil
+	case "list.create":
+		var rb models.CreateTasksListRB
+		if err := b.decode(op, &rb); err != nil {
+			return "", nil, err
+		}
+
+		if err := validator.Validate(rb); err != nil {
+			return "", nil, fmt.Errorf("validataion error: %s", err.Error())
+		}
+
+		tasksList, err := b.h.Services.CreateTasksList(ctx, rb.Build(b.uid))
+		if err != nil {
+			return "", nil, fmt.Errorf("can not add tasks list: %s", err.Error())
+		}
+
+		return tasksList.ID, tasksList, nil
+	case "list.update":
+		var rb models.UpdateTasksListRB
+		if err := b.decode(op, &rb); err != nil {
+			return "", nil, err
+		}
+
+		id, err := b.resolve(op.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		rb.ID = id
+
+		if err := validator.Validate(rb); err != nil {
+			return "", nil, fmt.Errorf("validataion error: %s", err.Error())
+		}
+
+		tasksList, err := b.h.Services.UpdateTasksList(ctx, id, b.uid, rb.Build())
+		if err != nil {
+			return "", nil, fmt.Errorf("can not update tasks list: %s", err.Error())
+		}
+
+		return id, tasksList, nil
+	case "list.delete":
+		var dto models.DeleteTasksListDTO
+		if len(op.Data) > 0 {
+			if err := b.decode(op, &dto); err != nil {
+				return "", nil, err
+			}
+		}
+
+		id, err := b.resolve(op.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		target, err := b.resolve(dto.TargetListID)
+		if err != nil {
+			return "", nil, err
+		}
+		dto.ID, dto.TargetListID = id, target
+
+		if err := validator.Validate(dto); err != nil {
+			return "", nil, fmt.Errorf("validataion error: %s", err.Error())
+		}
+
+		tlid, err := b.h.Services.DeleteTasksList(ctx, id, b.uid, dto.Mode, dto.TargetListID)
+		if err != nil {
+			return "", nil, fmt.Errorf("can not delete tasks list: %s", err.Error())
+		}
+
+		return tlid, nil, nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported operation %s on %s", op.Op, op.Type)
+}
+
+// decode reads the operation data into rb, rejecting unknown fields like
+// the single item endpoints do.
+func (b *batch) decode(op models.BatchOperation, rb interface{}) error {
+	var unmarshalErr *json.UnmarshalTypeError
+
+	if len(op.Data) == 0 {
+		return fmt.Errorf("bad Request: data is required")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(op.Data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(rb)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			return fmt.Errorf("bad Request: wrong type provided for field - %s", unmarshalErr.Field)
+		}
+		return fmt.Errorf("bad Request: %s", err.Error())
+	}
+
+	return nil
+}
+
+// resolve replaces a "$<temp_id>" reference with the id of the item created
+// under that temp id earlier in the batch.
+func (b *batch) resolve(id string) (string, error) {
+	if !strings.HasPrefix(id, "$") {
+		return id, nil
+	}
+
+	resolved, ok := b.tempIDs[strings.TrimPrefix(id, "$")]
+	if !ok {
+		return "", fmt.Errorf("unknown temp id %q", id)
+	}
+
+	return resolved, nil
+}
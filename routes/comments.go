@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type CommentsHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewCommentsHandler(router *Router) *CommentsHandler {
+	return &CommentsHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h CommentsHandler) RegisterCommentsRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tasks/:id/comments", h.middlewares.ApplyMiddlewares(
+		h.GetAllComments,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/comments", h.middlewares.ApplyMiddlewares(
+		h.AddNewComment,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPatch, "/tasks/:id/comments/:commentId", h.middlewares.ApplyMiddlewares(
+		h.UpdateComment,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks/:id/comments/:commentId", h.middlewares.ApplyMiddlewares(
+		h.DeleteComment,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h CommentsHandler) GetAllComments(w http.ResponseWriter, r *http.Request) {
+	page, err := models.ParsePage(r.URL.Query().Get("limit"), r.URL.Query().Get("offset"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	comments, err := h.Services.GetTaskComments(context.Background(), h.Parent.param(r, "id"), user.ID, page)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find comments: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	commentsBytes, _ := json.Marshal(comments)
+
+	h.Parent.send(w, string(commentsBytes), http.StatusOK)
+}
+
+func (h CommentsHandler) AddNewComment(w http.ResponseWriter, r *http.Request) {
+	var CreateCommentRB models.CreateCommentRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateCommentRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateCommentRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	comment, err := h.Services.AddComment(context.Background(), h.Parent.param(r, "id"), user.ID, &CreateCommentRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add comment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	commentBytes, _ := json.Marshal(comment)
+
+	h.Parent.send(w, string(commentBytes), http.StatusOK)
+}
+
+func (h CommentsHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	var UpdateCommentRB models.UpdateCommentRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&UpdateCommentRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(UpdateCommentRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	comment, err := h.Services.UpdateComment(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "commentId"), user.ID, &UpdateCommentRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not update comment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	commentBytes, _ := json.Marshal(comment)
+
+	h.Parent.send(w, string(commentBytes), http.StatusOK)
+}
+
+func (h CommentsHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	cid, err := h.Services.DeleteComment(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "commentId"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete comment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", cid), http.StatusOK)
+}
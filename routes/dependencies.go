@@ -0,0 +1,128 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type DependenciesHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewDependenciesHandler(router *Router) *DependenciesHandler {
+	return &DependenciesHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h DependenciesHandler) RegisterDependenciesRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tasks/:id/dependencies", h.middlewares.ApplyMiddlewares(
+		h.GetDependencies,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/dependencies", h.middlewares.ApplyMiddlewares(
+		h.AddNewDependency,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks/:id/dependencies/:blockerId", h.middlewares.ApplyMiddlewares(
+		h.DeleteDependency,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h DependenciesHandler) GetDependencies(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	dependencies, err := h.Services.GetTaskDependencies(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	dependenciesBytes, _ := json.Marshal(dependencies)
+
+	h.Parent.send(w, string(dependenciesBytes), http.StatusOK)
+}
+
+func (h DependenciesHandler) AddNewDependency(w http.ResponseWriter, r *http.Request) {
+	var CreateDependencyRB models.CreateDependencyRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateDependencyRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateDependencyRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	dependency, err := h.Services.AddDependency(context.Background(), h.Parent.param(r, "id"), user.ID, &CreateDependencyRB)
+	if errors.Is(err, services.ErrDependencyCycle) {
+		h.Parent.error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add dependency: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	dependencyBytes, _ := json.Marshal(dependency)
+
+	h.Parent.send(w, string(dependencyBytes), http.StatusOK)
+}
+
+func (h DependenciesHandler) DeleteDependency(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	bid, err := h.Services.RemoveDependency(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "blockerId"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete dependency: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", bid), http.StatusOK)
+}
@@ -0,0 +1,232 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type FoldersHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewFoldersHandler(router *Router) *FoldersHandler {
+	return &FoldersHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h FoldersHandler) RegisterFoldersRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/folders/", h.middlewares.ApplyMiddlewares(
+		h.GetAllFolders,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/folders/", h.middlewares.ApplyMiddlewares(
+		h.AddNewFolder,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPatch, "/folders/", h.middlewares.ApplyMiddlewares(
+		h.UpdateFolder,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/folders/", h.middlewares.ApplyMiddlewares(
+		h.DeleteFolder,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/folders/:id/move", h.middlewares.ApplyMiddlewares(
+		h.MoveFolder,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h FoldersHandler) GetAllFolders(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	folders, err := h.Services.Folders.GetAllUserFolders(context.Background(), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user folders: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	foldersBytes, _ := json.Marshal(folders)
+
+	h.Parent.send(w, string(foldersBytes), http.StatusOK)
+}
+
+func (h FoldersHandler) AddNewFolder(w http.ResponseWriter, r *http.Request) {
+	var CreateFolderRB models.CreateFolderRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateFolderRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateFolderRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	CreateFolderDTO := CreateFolderRB.Build(user.ID)
+
+	folder, err := h.Services.Folders.AddFolder(context.Background(), CreateFolderDTO)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add folder: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	folderBytes, _ := json.Marshal(folder)
+
+	h.Parent.send(w, string(folderBytes), http.StatusOK)
+}
+
+func (h FoldersHandler) UpdateFolder(w http.ResponseWriter, r *http.Request) {
+	var UpdateFolderRB models.UpdateFolderRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&UpdateFolderRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(UpdateFolderRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	UpdateFolderDTO := UpdateFolderRB.Build()
+
+	folder, err := h.Services.Folders.UpdateFolder(context.Background(), UpdateFolderRB.ID, user.ID, UpdateFolderDTO)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not update folder: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	folderBytes, _ := json.Marshal(folder)
+
+	h.Parent.send(w, string(folderBytes), http.StatusOK)
+}
+
+func (h FoldersHandler) DeleteFolder(w http.ResponseWriter, r *http.Request) {
+	var DeleteFolderDTO models.DeleteFolderDTO
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&DeleteFolderDTO)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(DeleteFolderDTO); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	fid, err := h.Services.DeleteFolder(context.Background(), DeleteFolderDTO.ID, user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete folder: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", fid), http.StatusOK)
+}
+
+func (h FoldersHandler) MoveFolder(w http.ResponseWriter, r *http.Request) {
+	var MoveRB models.MoveRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&MoveRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(MoveRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	folder, err := h.Services.Folders.MoveFolder(context.Background(), h.Parent.param(r, "id"), user.ID, &MoveRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not move folder: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	folderBytes, _ := json.Marshal(folder)
+
+	h.Parent.send(w, string(folderBytes), http.StatusOK)
+}
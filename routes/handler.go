@@ -38,10 +38,38 @@ func (r *Router) Register() {
 	usersHandler := NewUsersHandler(r)
 	tasksListsHandler := NewTasksListsHandler(r)
 	tasksHandler := NewTasksHandler(r)
+	viewsHandler := NewViewsHandler(r)
+	remindersHandler := NewRemindersHandler(r)
+	tagsHandler := NewTagsHandler(r)
+	foldersHandler := NewFoldersHandler(r)
+	membersHandler := NewMembersHandler(r)
+	shareLinksHandler := NewShareLinksHandler(r)
+	commentsHandler := NewCommentsHandler(r)
+	attachmentsHandler := NewAttachmentsHandler(r)
+	activityHandler := NewActivityHandler(r)
+	operationsHandler := NewOperationsHandler(r)
+	dependenciesHandler := NewDependenciesHandler(r)
+	timeEntriesHandler := NewTimeEntriesHandler(r)
+	batchHandler := NewBatchHandler(r)
+	trashHandler := NewTrashHandler(r)
 
 	usersHandler.RegisterUsersRoutes()
 	tasksListsHandler.RegisterTasksListsRoutes()
 	tasksHandler.RegisterTasksRoutes()
+	viewsHandler.RegisterViewsRoutes()
+	remindersHandler.RegisterRemindersRoutes()
+	tagsHandler.RegisterTagsRoutes()
+	foldersHandler.RegisterFoldersRoutes()
+	membersHandler.RegisterMembersRoutes()
+	shareLinksHandler.RegisterShareLinksRoutes()
+	commentsHandler.RegisterCommentsRoutes()
+	attachmentsHandler.RegisterAttachmentsRoutes()
+	activityHandler.RegisterActivityRoutes()
+	operationsHandler.RegisterOperationsRoutes()
+	dependenciesHandler.RegisterDependenciesRoutes()
+	timeEntriesHandler.RegisterTimeEntriesRoutes()
+	batchHandler.RegisterBatchRoutes()
+	trashHandler.RegisterTrashRoutes()
 }
 
 func (router *Router) getUser(r *http.Request) (u *models.User, err error) {
@@ -73,6 +101,31 @@ func (router *Router) getUser(r *http.Request) (u *models.User, err error) {
 	return u, err
 }
 
+// param returns a named parameter of the matched route.
+func (router *Router) param(r *http.Request, name string) string {
+	return httprouter.ParamsFromContext(r.Context()).ByName(name)
+}
+
+// static serves a fixed path segment that shares its position with a
+// wildcard, e.g. /tasks/clear next to /tasks/:id/subs. httprouter can not
+// register both, so the wildcard route is registered and requests whose
+// parameter equals segment go to handler instead of fallback.
+func (router *Router) static(param, segment string, handler, fallback http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if router.param(r, param) == segment {
+			handler(w, r)
+			return
+		}
+
+		if fallback == nil {
+			router.error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		fallback(w, r)
+	}
+}
+
 func (router *Router) send(w http.ResponseWriter, result string, httpStatusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(httpStatusCode)
@@ -84,3 +137,10 @@ func (router *Router) error(w http.ResponseWriter, message string, httpStatusCod
 	w.WriteHeader(httpStatusCode)
 	w.Write([]byte(fmt.Sprintf(`{"success": false, "error": %s}`, message)))
 }
+
+// errorWithResult reports a failure together with what was done before it.
+func (router *Router) errorWithResult(w http.ResponseWriter, message string, result string, httpStatusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusCode)
+	w.Write([]byte(fmt.Sprintf(`{"success": false, "error": %s, "result": %s}`, message, result)))
+}
@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type MembersHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewMembersHandler(router *Router) *MembersHandler {
+	return &MembersHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h MembersHandler) RegisterMembersRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tasks-lists/:id/members", h.middlewares.ApplyMiddlewares(
+		h.GetAllMembers,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks-lists/:id/members", h.middlewares.ApplyMiddlewares(
+		h.InviteMember,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPatch, "/tasks-lists/:id/members/:memberId", h.middlewares.ApplyMiddlewares(
+		h.UpdateMember,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks-lists/:id/members/:memberId", h.middlewares.ApplyMiddlewares(
+		h.RemoveMember,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/invitations/", h.middlewares.ApplyMiddlewares(
+		h.GetAllInvitations,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/invitations/:id/accept", h.middlewares.ApplyMiddlewares(
+		h.AcceptInvitation,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/invitations/:id/decline", h.middlewares.ApplyMiddlewares(
+		h.DeclineInvitation,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h MembersHandler) GetAllMembers(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	members, err := h.Services.GetTasksListMembers(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find members: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	membersBytes, _ := json.Marshal(members)
+
+	h.Parent.send(w, string(membersBytes), http.StatusOK)
+}
+
+func (h MembersHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	var InviteMemberRB models.InviteMemberRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&InviteMemberRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(InviteMemberRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	member, err := h.Services.InviteMember(context.Background(), h.Parent.param(r, "id"), user.ID, &InviteMemberRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not invite member: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	memberBytes, _ := json.Marshal(member)
+
+	h.Parent.send(w, string(memberBytes), http.StatusOK)
+}
+
+func (h MembersHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	var UpdateMemberRB models.UpdateMemberRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&UpdateMemberRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(UpdateMemberRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	member, err := h.Services.UpdateMemberRole(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "memberId"), user.ID, UpdateMemberRB.Role)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not update member: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	memberBytes, _ := json.Marshal(member)
+
+	h.Parent.send(w, string(memberBytes), http.StatusOK)
+}
+
+func (h MembersHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	mid, err := h.Services.RemoveMember(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "memberId"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not remove member: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", mid), http.StatusOK)
+}
+
+func (h MembersHandler) GetAllInvitations(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	invitations, err := h.Services.Members.GetUserInvitations(context.Background(), user.Email)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find invitations: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	invitationsBytes, _ := json.Marshal(invitations)
+
+	h.Parent.send(w, string(invitationsBytes), http.StatusOK)
+}
+
+func (h MembersHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondInvitation(w, r, true)
+}
+
+func (h MembersHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondInvitation(w, r, false)
+}
+
+func (h MembersHandler) respondInvitation(w http.ResponseWriter, r *http.Request, accept bool) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	member, err := h.Services.RespondInvitation(context.Background(), h.Parent.param(r, "id"), *user, accept)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find invitation: %s", err.Error()), http.StatusNotFound)
+		return
+	}
+
+	memberBytes, _ := json.Marshal(member)
+
+	h.Parent.send(w, string(memberBytes), http.StatusOK)
+}
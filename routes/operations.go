@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+)
+
+const maxUndoSteps = 50
+
+type OperationsHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewOperationsHandler(router *Router) *OperationsHandler {
+	return &OperationsHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h OperationsHandler) RegisterOperationsRoutes() {
+	h.Router.HandlerFunc(http.MethodPost, "/undo", h.middlewares.ApplyMiddlewares(
+		h.Undo,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/redo", h.middlewares.ApplyMiddlewares(
+		h.Redo,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h OperationsHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	h.replay(w, r, h.Services.Undo)
+}
+
+func (h OperationsHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	h.replay(w, r, h.Services.Redo)
+}
+
+// replay runs undo or redo for ?steps= operations, one by default. Nothing
+// left to replay is reported as 404 and a task changed since as 409.
+func (h OperationsHandler) replay(w http.ResponseWriter, r *http.Request, replay func(ctx context.Context, uid string, steps int) (models.UndoResult, error)) {
+	steps := 1
+	if v := r.URL.Query().Get("steps"); v != "" {
+		var err error
+		steps, err = strconv.Atoi(v)
+		if err != nil || steps < 1 || steps > maxUndoSteps {
+			h.Parent.error(w, fmt.Sprintf("bad request: steps must be between 1 and %d", maxUndoSteps), http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := replay(context.Background(), user.ID, steps)
+	if err == services.ErrNothingToUndo || err == services.ErrNothingToRedo {
+		h.Parent.error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrUndoConflict) {
+		h.Parent.error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not replay operations: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	resultBytes, _ := json.Marshal(result)
+
+	h.Parent.send(w, string(resultBytes), http.StatusOK)
+}
@@ -0,0 +1,160 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type RemindersHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewRemindersHandler(router *Router) *RemindersHandler {
+	return &RemindersHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h RemindersHandler) RegisterRemindersRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/reminders/", h.middlewares.ApplyMiddlewares(
+		h.GetAllReminders,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/reminders/", h.middlewares.ApplyMiddlewares(
+		h.AddNewReminder,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/reminders/", h.middlewares.ApplyMiddlewares(
+		h.DeleteReminder,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h RemindersHandler) GetAllReminders(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	reminders, err := h.Services.Reminders.GetAllUserReminders(context.Background(), user.ID, r.URL.Query().Get("task_id"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user reminders: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	remindersBytes, _ := json.Marshal(reminders)
+
+	h.Parent.send(w, string(remindersBytes), http.StatusOK)
+}
+
+func (h RemindersHandler) AddNewReminder(w http.ResponseWriter, r *http.Request) {
+	var CreateReminderRB models.CreateReminderRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateReminderRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateReminderRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if (CreateReminderRB.BeforeMinutes == nil) == (CreateReminderRB.At == nil) {
+		h.Parent.error(w, "validataion error: exactly one of before_minutes and at is required", http.StatusBadRequest)
+		return
+	}
+
+	if CreateReminderRB.BeforeMinutes != nil && *CreateReminderRB.BeforeMinutes < 0 {
+		h.Parent.error(w, "validataion error: before_minutes can not be negative", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := h.Services.AuthorizeTask(context.Background(), CreateReminderRB.TaskID, user.ID, models.RoleEditor)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task: %s", err.Error()), http.StatusNotFound)
+		return
+	}
+
+	reminder, err := h.Services.Reminders.AddReminder(context.Background(), CreateReminderRB.Build(user.ID, task))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add reminder: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	reminderBytes, _ := json.Marshal(reminder)
+
+	h.Parent.send(w, string(reminderBytes), http.StatusOK)
+}
+
+func (h RemindersHandler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	var DeleteReminderDTO models.DeleteReminderDTO
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&DeleteReminderDTO)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(DeleteReminderDTO); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	rid, err := h.Services.Reminders.DeleteReminder(context.Background(), DeleteReminderDTO.ID, user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete reminder: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", rid), http.StatusOK)
+}
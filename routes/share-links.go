@@ -0,0 +1,234 @@
+package routes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/validator.v2"
+)
+
+// Failed attempts to open a share link are counted per token and per client
+// address, once either count reaches its limit further attempts are
+// rejected until the window expires.
+const (
+	shareAttemptsWindow   = 15 * time.Minute
+	shareAttemptsPerToken = 10
+	shareAttemptsPerIP    = 50
+)
+
+type ShareLinksHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewShareLinksHandler(router *Router) *ShareLinksHandler {
+	return &ShareLinksHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h ShareLinksHandler) RegisterShareLinksRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tasks-lists/:id/share-links", h.middlewares.ApplyMiddlewares(
+		h.GetAllShareLinks,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks-lists/:id/share-links", h.middlewares.ApplyMiddlewares(
+		h.AddNewShareLink,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks-lists/:id/share-links/:linkId", h.middlewares.ApplyMiddlewares(
+		h.RevokeShareLink,
+		h.middlewares.ForAuth,
+	))
+	// Served without authentication, the token is the credential. Protected
+	// links expect their password in the X-Share-Password header.
+	h.Router.HandlerFunc(http.MethodGet, "/shared/:token", h.GetSharedTasksList)
+}
+
+func (h ShareLinksHandler) GetAllShareLinks(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	links, err := h.Services.GetShareLinks(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find share links: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	linksBytes, _ := json.Marshal(links)
+
+	h.Parent.send(w, string(linksBytes), http.StatusOK)
+}
+
+func (h ShareLinksHandler) AddNewShareLink(w http.ResponseWriter, r *http.Request) {
+	var CreateShareLinkRB models.CreateShareLinkRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateShareLinkRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateShareLinkRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	link, err := h.Services.CreateShareLink(context.Background(), h.Parent.param(r, "id"), user.ID, &CreateShareLinkRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add share link: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	linkBytes, _ := json.Marshal(link)
+
+	h.Parent.send(w, string(linkBytes), http.StatusOK)
+}
+
+func (h ShareLinksHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	lkid, err := h.Services.RevokeShareLink(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "linkId"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not revoke share link: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", lkid), http.StatusOK)
+}
+
+func (h ShareLinksHandler) GetSharedTasksList(w http.ResponseWriter, r *http.Request) {
+	token := h.Parent.param(r, "token")
+	keys := shareAttemptKeys(r, token)
+
+	limited, err := h.attemptsExceeded(context.Background(), keys)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not check share link attempts: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if limited {
+		h.Parent.error(w, "too many attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	shared, err := h.Services.GetSharedTasksList(context.Background(), token, r.Header.Get("X-Share-Password"))
+	if err == mongo.ErrNoDocuments || err == services.ErrSharePassword {
+		if err := h.countAttempt(context.Background(), keys); err != nil {
+			h.logger.Error(fmt.Sprintf("can not count share link attempt: %s", err.Error()))
+		}
+	}
+	if err == mongo.ErrNoDocuments {
+		h.Parent.error(w, "share link not found", http.StatusNotFound)
+		return
+	}
+	if err == services.ErrSharePassword {
+		h.Parent.error(w, "wrong password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find shared tasks list: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	sharedBytes, _ := json.Marshal(shared)
+
+	h.Parent.send(w, string(sharedBytes), http.StatusOK)
+}
+
+type shareAttemptKey struct {
+	key   string
+	limit int64
+}
+
+// shareAttemptKeys returns the redis keys counting failed attempts on the
+// token and from the client address. The token is hashed so the keys do not
+// hold usable credentials.
+func shareAttemptKeys(r *http.Request, token string) []shareAttemptKey {
+	sum := sha256.Sum256([]byte(token))
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return []shareAttemptKey{
+		{key: "share-links:attempts:token:" + hex.EncodeToString(sum[:]), limit: shareAttemptsPerToken},
+		{key: "share-links:attempts:ip:" + ip, limit: shareAttemptsPerIP},
+	}
+}
+
+func (h ShareLinksHandler) attemptsExceeded(ctx context.Context, keys []shareAttemptKey) (bool, error) {
+	for _, k := range keys {
+		count, err := h.redis.Get(ctx, k.key).Int64()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if count >= k.limit {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (h ShareLinksHandler) countAttempt(ctx context.Context, keys []shareAttemptKey) error {
+	for _, k := range keys {
+		count, err := h.redis.Incr(ctx, k.key).Result()
+		if err != nil {
+			return err
+		}
+		if count == 1 {
+			if err := h.redis.Expire(ctx, k.key, shareAttemptsWindow).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
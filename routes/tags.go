@@ -0,0 +1,190 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+type TagsHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewTagsHandler(router *Router) *TagsHandler {
+	return &TagsHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h TagsHandler) RegisterTagsRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/tags/", h.middlewares.ApplyMiddlewares(
+		h.GetAllTags,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tags/", h.middlewares.ApplyMiddlewares(
+		h.AddNewTag,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPatch, "/tags/", h.middlewares.ApplyMiddlewares(
+		h.UpdateTag,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tags/", h.middlewares.ApplyMiddlewares(
+		h.DeleteTag,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h TagsHandler) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.Services.Tags.GetAllUserTags(context.Background(), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tags: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tagsBytes, _ := json.Marshal(tags)
+
+	h.Parent.send(w, string(tagsBytes), http.StatusOK)
+}
+
+func (h TagsHandler) AddNewTag(w http.ResponseWriter, r *http.Request) {
+	var CreateTagRB models.CreateTagRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateTagRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateTagRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	CreateTagDTO := CreateTagRB.Build(user.ID)
+
+	tag, err := h.Services.Tags.AddTag(context.Background(), CreateTagDTO)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add tag: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tagBytes, _ := json.Marshal(tag)
+
+	h.Parent.send(w, string(tagBytes), http.StatusOK)
+}
+
+func (h TagsHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	var UpdateTagRB models.UpdateTagRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&UpdateTagRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(UpdateTagRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	UpdateTagDTO := UpdateTagRB.Build()
+
+	tag, err := h.Services.Tags.UpdateTag(context.Background(), UpdateTagRB.ID, user.ID, UpdateTagDTO)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not update tag: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tagBytes, _ := json.Marshal(tag)
+
+	h.Parent.send(w, string(tagBytes), http.StatusOK)
+}
+
+func (h TagsHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	var DeleteTagDTO models.DeleteTagDTO
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&DeleteTagDTO)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(DeleteTagDTO); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tgid, err := h.Services.DeleteTag(context.Background(), DeleteTagDTO.ID, user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete tag: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, fmt.Sprintf("\"%s\"", tgid), http.StatusOK)
+}
@@ -10,6 +10,7 @@ import (
 	"main/services"
 	"main/utils/logging"
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/redis/go-redis/v9"
@@ -53,6 +54,30 @@ func (h TasksListsHandler) RegisterTasksListsRoutes() {
 		h.DeleteTasksList,
 		h.middlewares.ForAuth,
 	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks-lists/:id/move", h.middlewares.ApplyMiddlewares(
+		h.MoveTasksList,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks-lists/:id/archive", h.middlewares.ApplyMiddlewares(
+		h.ArchiveTasksList,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks-lists/:id/unarchive", h.middlewares.ApplyMiddlewares(
+		h.UnarchiveTasksList,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPatch, "/tasks-lists/:id/statuses", h.middlewares.ApplyMiddlewares(
+		h.UpdateTasksListStatuses,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/tasks-lists/:id/board", h.middlewares.ApplyMiddlewares(
+		h.GetBoard,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/tasks-lists-archived/counts", h.middlewares.ApplyMiddlewares(
+		h.GetArchivedCounts,
+		h.middlewares.ForAuth,
+	))
 }
 
 func (h TasksListsHandler) GetAllTasksLists(w http.ResponseWriter, r *http.Request) {
@@ -61,9 +86,41 @@ func (h TasksListsHandler) GetAllTasksLists(w http.ResponseWriter, r *http.Reque
 		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
 	}
 
-	tasks, err := h.Services.TasksLists.GetAllUserTasksLists(context.Background(), user.ID)
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	today, err := startOfToday(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := h.Services.GetAllTasksLists(context.Background(), user.ID, include)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Services.TasksLists.AttachStats(context.Background(), user.ID, tasks, time.Now(), today)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not count user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("tree") == "true" {
+		folders, err := h.Services.Folders.GetAllUserFolders(context.Background(), user.ID)
+		if err != nil {
+			h.Parent.error(w, fmt.Sprintf("can not find user folders: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		treeBytes, _ := json.Marshal(models.BuildTasksListsTree(folders, tasks))
+
+		h.Parent.send(w, string(treeBytes), http.StatusOK)
+		return
 	}
 
 	tasksListBytes, err := json.Marshal(tasks)
@@ -103,7 +160,7 @@ func (h TasksListsHandler) AddNewTasksList(w http.ResponseWriter, r *http.Reques
 
 	CreateTasksListDTO := CreateTasksListRB.Build(user.ID)
 
-	tasksList, err := h.Services.TasksLists.AddTasksList(context.Background(), CreateTasksListDTO)
+	tasksList, err := h.Services.CreateTasksList(context.Background(), CreateTasksListDTO)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not add tasks list: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -143,7 +200,7 @@ func (h TasksListsHandler) UpdateTasksList(w http.ResponseWriter, r *http.Reques
 
 	UpdateTasksListDTO := UpdateTasksListRB.Build()
 
-	tasksList, err := h.Services.TasksLists.UpdateTasksList(context.Background(), UpdateTasksListRB.ID, user.ID, UpdateTasksListDTO)
+	tasksList, err := h.Services.UpdateTasksList(context.Background(), UpdateTasksListRB.ID, user.ID, UpdateTasksListDTO)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not update tasks list: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -181,7 +238,7 @@ func (h TasksListsHandler) DeleteTasksList(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	tlid, err := h.Services.TasksLists.DeleteTasksList(context.Background(), DeleteTasksListDTO.ID, user.ID)
+	tlid, err := h.Services.DeleteTasksList(context.Background(), DeleteTasksListDTO.ID, user.ID, DeleteTasksListDTO.Mode, DeleteTasksListDTO.TargetListID)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not delete tasks list: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -189,3 +246,157 @@ func (h TasksListsHandler) DeleteTasksList(w http.ResponseWriter, r *http.Reques
 
 	h.Parent.send(w, fmt.Sprintf("\"%s\"", tlid), http.StatusOK)
 }
+
+func (h TasksListsHandler) MoveTasksList(w http.ResponseWriter, r *http.Request) {
+	var MoveRB models.MoveRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&MoveRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(MoveRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksList, err := h.Services.TasksLists.MoveTasksList(context.Background(), h.Parent.param(r, "id"), user.ID, &MoveRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not move tasks list: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksListBytes, _ := json.Marshal(tasksList)
+
+	h.Parent.send(w, string(tasksListBytes), http.StatusOK)
+}
+
+func (h TasksListsHandler) ArchiveTasksList(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksList, err := h.Services.TasksLists.ArchiveTasksList(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not archive tasks list: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksListBytes, _ := json.Marshal(tasksList)
+
+	h.Parent.send(w, string(tasksListBytes), http.StatusOK)
+}
+
+func (h TasksListsHandler) UnarchiveTasksList(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksList, err := h.Services.TasksLists.UnarchiveTasksList(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not unarchive tasks list: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksListBytes, _ := json.Marshal(tasksList)
+
+	h.Parent.send(w, string(tasksListBytes), http.StatusOK)
+}
+
+func (h TasksListsHandler) GetArchivedCounts(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	counts, err := h.Services.GetArchivedCounts(context.Background(), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not count archived items: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	countsBytes, _ := json.Marshal(counts)
+
+	h.Parent.send(w, string(countsBytes), http.StatusOK)
+}
+
+func (h TasksListsHandler) UpdateTasksListStatuses(w http.ResponseWriter, r *http.Request) {
+	var UpdateStatusesRB models.UpdateStatusesRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&UpdateStatusesRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(UpdateStatusesRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := UpdateStatusesRB.Build()
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksList, err := h.Services.UpdateTasksListStatuses(context.Background(), h.Parent.param(r, "id"), user.ID, statuses)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not update tasks list statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksListBytes, _ := json.Marshal(tasksList)
+
+	h.Parent.send(w, string(tasksListBytes), http.StatusOK)
+}
+
+func (h TasksListsHandler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	board, err := h.Services.GetBoard(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find board: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	boardBytes, _ := json.Marshal(board)
+
+	h.Parent.send(w, string(boardBytes), http.StatusOK)
+}
@@ -10,6 +10,7 @@ import (
 	"main/services"
 	"main/utils/logging"
 	"net/http"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/redis/go-redis/v9"
@@ -53,9 +54,29 @@ func (h TasksHandler) RegisterTasksRoutes() {
 		h.DeleteTask,
 		h.middlewares.ForAuth,
 	))
-	h.Router.HandlerFunc(http.MethodDelete, "/tasks/clear", h.middlewares.ApplyMiddlewares(
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks/:id", h.Parent.static("id", "clear", h.middlewares.ApplyMiddlewares(
 		h.DeleteAllTask,
 		h.middlewares.ForAuth,
+	), nil))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/move", h.middlewares.ApplyMiddlewares(
+		h.Parent.static("id", "bulk", h.BulkMoveTasks, h.MoveTask),
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/copy", h.middlewares.ApplyMiddlewares(
+		h.Parent.static("id", "bulk", h.BulkCopyTasks, nil),
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/subs", h.middlewares.ApplyMiddlewares(
+		h.AddSub,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPatch, "/tasks/:id/subs/:subId", h.middlewares.ApplyMiddlewares(
+		h.UpdateSub,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/tasks/:id/subs/:subId", h.middlewares.ApplyMiddlewares(
+		h.DeleteSub,
+		h.middlewares.ForAuth,
 	))
 }
 
@@ -63,16 +84,55 @@ func (h TasksHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
 	user, err := h.Parent.getUser(r)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	filter := models.TasksFilter{
+		Sort:      r.URL.Query().Get("sort"),
+		TagsMatch: r.URL.Query().Get("tags_match"),
+		Assignee:  r.URL.Query().Get("assignee"),
+	}
+	if tags := r.URL.Query().Get("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+
+	if err := validator.Validate(filter); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
 	}
 
-	tasks, err := h.Services.Tasks.GetAllUserTasks(context.Background(), user.ID)
+	scope, err := h.Services.GetTasksScope(context.Background(), user.ID, include)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks lists: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.Tasks.GetAllUserTasks(context.Background(), scope, filter)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachBlocked(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachStatuses(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
 	}
 
 	tasksBytes, err := json.Marshal(tasks)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
 	}
 
 	h.Parent.send(w, string(tasksBytes), http.StatusOK)
@@ -107,13 +167,7 @@ func (h TasksHandler) AddNewTask(w http.ResponseWriter, r *http.Request) {
 
 	createTaskDTO := CreateTaskRB.Build(user.ID)
 
-	_, err = h.Services.TasksLists.GetUserTasksList(context.Background(), createTaskDTO.ListID, user.ID)
-	if err != nil {
-		h.Parent.error(w, fmt.Sprintf("can not find tasks list: %s", err.Error()), http.StatusInternalServerError)
-		return
-	}
-
-	task, err := h.Services.Tasks.AddTask(context.Background(), createTaskDTO)
+	task, err := h.Services.CreateTask(context.Background(), createTaskDTO)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not add task: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -153,13 +207,7 @@ func (h TasksHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 
 	updateTaskDTO := UpdateTaskRB.Build()
 
-	_, err = h.Services.TasksLists.GetUserTasksList(context.Background(), updateTaskDTO.ListID, user.ID)
-	if err != nil {
-		h.Parent.error(w, fmt.Sprintf("can not find tasks list: %s", err.Error()), http.StatusInternalServerError)
-		return
-	}
-
-	task, err := h.Services.Tasks.UpdateTask(context.Background(), UpdateTaskRB.ID, user.ID, updateTaskDTO)
+	task, err := h.Services.UpdateTask(context.Background(), UpdateTaskRB.ID, user.ID, updateTaskDTO)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not update task: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -197,7 +245,7 @@ func (h TasksHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tid, err := h.Services.Tasks.DeleteTask(context.Background(), deleteTaskDTO.ID, user.ID)
+	tid, err := h.Services.DeleteTask(context.Background(), deleteTaskDTO.ID, user.ID)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not delete task: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -213,7 +261,7 @@ func (h TasksHandler) DeleteAllTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.Services.Tasks.DeleteAllTask(context.Background(), user.ID)
+	err = h.Services.DeleteAllTasks(context.Background(), user.ID)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not delete task: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -221,3 +269,217 @@ func (h TasksHandler) DeleteAllTask(w http.ResponseWriter, r *http.Request) {
 
 	h.Parent.send(w, "\"\"", http.StatusOK)
 }
+
+func (h TasksHandler) AddSub(w http.ResponseWriter, r *http.Request) {
+	var CreateSubTaskRB models.CreateSubTaskRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&CreateSubTaskRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(CreateSubTaskRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := h.Services.AddSub(context.Background(), h.Parent.param(r, "id"), user.ID, &CreateSubTaskRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not add subtask: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	taskBytes, _ := json.Marshal(task)
+
+	h.Parent.send(w, string(taskBytes), http.StatusOK)
+}
+
+func (h TasksHandler) UpdateSub(w http.ResponseWriter, r *http.Request) {
+	var UpdateSubTaskRB models.UpdateSubTaskRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&UpdateSubTaskRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if UpdateSubTaskRB.Title != nil && *UpdateSubTaskRB.Title == "" {
+		h.Parent.error(w, "validataion error: title can not be empty", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := h.Services.UpdateSub(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "subId"), user.ID, &UpdateSubTaskRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not update subtask: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	taskBytes, _ := json.Marshal(task)
+
+	h.Parent.send(w, string(taskBytes), http.StatusOK)
+}
+
+func (h TasksHandler) DeleteSub(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	task, err := h.Services.DeleteSub(context.Background(), h.Parent.param(r, "id"), h.Parent.param(r, "subId"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not delete subtask: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	taskBytes, _ := json.Marshal(task)
+
+	h.Parent.send(w, string(taskBytes), http.StatusOK)
+}
+
+func (h TasksHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	var MoveRB models.MoveRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&MoveRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(MoveRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	current, err := h.Services.AuthorizeTask(context.Background(), h.Parent.param(r, "id"), user.ID, models.RoleEditor)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task: %s", err.Error()), http.StatusNotFound)
+		return
+	}
+
+	task, err := h.Services.Tasks.MoveTask(context.Background(), current.ID, current.UserID, &MoveRB)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not move task: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	taskBytes, _ := json.Marshal(task)
+
+	h.Parent.send(w, string(taskBytes), http.StatusOK)
+}
+
+func (h TasksHandler) BulkMoveTasks(w http.ResponseWriter, r *http.Request) {
+	var BulkTasksRB models.BulkTasksRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&BulkTasksRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(BulkTasksRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.BulkMoveTasks(context.Background(), BulkTasksRB.IDs, user.ID, BulkTasksRB.ListID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not move tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
+
+func (h TasksHandler) BulkCopyTasks(w http.ResponseWriter, r *http.Request) {
+	var BulkTasksRB models.BulkTasksRB
+	var unmarshalErr *json.UnmarshalTypeError
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&BulkTasksRB)
+	if err != nil {
+		if errors.As(err, &unmarshalErr) {
+			h.Parent.error(w, fmt.Sprintf("bad Request: wrong type provided for field - %s", unmarshalErr.Field), http.StatusBadRequest)
+		} else {
+			h.Parent.error(w, fmt.Sprintf("bad Request: %s", err.Error()), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if err := validator.Validate(BulkTasksRB); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.BulkCopyTasks(context.Background(), BulkTasksRB.IDs, user.ID, BulkTasksRB.ListID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not copy tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
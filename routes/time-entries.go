@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+)
+
+type TimeEntriesHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewTimeEntriesHandler(router *Router) *TimeEntriesHandler {
+	return &TimeEntriesHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h TimeEntriesHandler) RegisterTimeEntriesRoutes() {
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/timer/start", h.middlewares.ApplyMiddlewares(
+		h.StartTimer,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/tasks/:id/timer/stop", h.middlewares.ApplyMiddlewares(
+		h.StopTimer,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/reports/time", h.middlewares.ApplyMiddlewares(
+		h.GetTimeReport,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h TimeEntriesHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	entry, err := h.Services.StartTimer(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err == services.ErrTimerRunning {
+		h.Parent.error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not start timer: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	entryBytes, _ := json.Marshal(entry)
+
+	h.Parent.send(w, string(entryBytes), http.StatusOK)
+}
+
+func (h TimeEntriesHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	entry, err := h.Services.StopTimer(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if errors.Is(err, services.ErrNoTimer) {
+		h.Parent.error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not stop timer: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	entryBytes, _ := json.Marshal(entry)
+
+	h.Parent.send(w, string(entryBytes), http.StatusOK)
+}
+
+func (h TimeEntriesHandler) GetTimeReport(w http.ResponseWriter, r *http.Request) {
+	query, err := models.ParseTimeReportQuery(
+		r.URL.Query().Get("from"),
+		r.URL.Query().Get("to"),
+		r.URL.Query().Get("group_by"),
+		r.URL.Query().Get("tz"),
+	)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	report, err := h.Services.GetTimeReport(context.Background(), user.ID, query)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not build time report: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	reportBytes, _ := json.Marshal(report)
+
+	h.Parent.send(w, string(reportBytes), http.StatusOK)
+}
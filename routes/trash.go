@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/middlewares"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+)
+
+type TrashHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewTrashHandler(router *Router) *TrashHandler {
+	return &TrashHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h TrashHandler) RegisterTrashRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/trash", h.middlewares.ApplyMiddlewares(
+		h.GetTrash,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodPost, "/trash/:id/restore", h.middlewares.ApplyMiddlewares(
+		h.Restore,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodDelete, "/trash", h.middlewares.ApplyMiddlewares(
+		h.EmptyTrash,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	trash, err := h.Services.GetTrash(context.Background(), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find trash: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	trashBytes, _ := json.Marshal(trash)
+
+	h.Parent.send(w, string(trashBytes), http.StatusOK)
+}
+
+func (h TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	restored, err := h.Services.RestoreFromTrash(context.Background(), h.Parent.param(r, "id"), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not restore: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	restoredBytes, _ := json.Marshal(restored)
+
+	h.Parent.send(w, string(restoredBytes), http.StatusOK)
+}
+
+func (h TrashHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Services.EmptyTrash(context.Background(), user.ID)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not empty trash: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.Parent.send(w, "\"\"", http.StatusOK)
+}
@@ -142,7 +142,7 @@ func (h UsersHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	oid, err := h.Services.Users.CreateUser(context.Background(), buildedUser)
+	oid, err := h.Services.CreateUser(context.Background(), buildedUser)
 	if err != nil {
 		h.Parent.error(w, fmt.Sprintf("can not create user: %s", err.Error()), http.StatusBadRequest)
 		return
@@ -0,0 +1,327 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/middlewares"
+	"main/models"
+	"main/services"
+	"main/utils/logging"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/validator.v2"
+)
+
+const defaultUpcomingDays = 7
+
+type ViewsHandler struct {
+	Parent      *Router
+	Router      *httprouter.Router
+	Services    *services.Services
+	middlewares *middlewares.Middlewares
+	logger      *logging.Logger
+	redis       *redis.Client
+}
+
+func NewViewsHandler(router *Router) *ViewsHandler {
+	return &ViewsHandler{
+		Parent:      router,
+		Router:      router.Router,
+		Services:    router.Services,
+		middlewares: router.middlewares,
+		logger:      router.logger,
+		redis:       router.redis,
+	}
+}
+
+func (h ViewsHandler) RegisterViewsRoutes() {
+	h.Router.HandlerFunc(http.MethodGet, "/views/today", h.middlewares.ApplyMiddlewares(
+		h.GetToday,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/views/upcoming", h.middlewares.ApplyMiddlewares(
+		h.GetUpcoming,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/views/overdue", h.middlewares.ApplyMiddlewares(
+		h.GetOverdue,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/views/starred", h.middlewares.ApplyMiddlewares(
+		h.GetStarred,
+		h.middlewares.ForAuth,
+	))
+	h.Router.HandlerFunc(http.MethodGet, "/views/assigned-to-me", h.middlewares.ApplyMiddlewares(
+		h.GetAssignedToMe,
+		h.middlewares.ForAuth,
+	))
+}
+
+func (h ViewsHandler) GetToday(w http.ResponseWriter, r *http.Request) {
+	today, err := startOfToday(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.TasksFilter{Assignee: r.URL.Query().Get("assignee")}
+	if err := validator.Validate(filter); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	scope, err := h.Services.GetTasksScope(context.Background(), user.ID, include)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks lists: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.Tasks.GetUserTasksDueBetween(context.Background(), scope, filter.Assignee, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachBlocked(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachStatuses(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
+
+func (h ViewsHandler) GetUpcoming(w http.ResponseWriter, r *http.Request) {
+	today, err := startOfToday(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	days := defaultUpcomingDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 1 || days > 365 {
+			h.Parent.error(w, "bad request: days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+	}
+
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.TasksFilter{Assignee: r.URL.Query().Get("assignee")}
+	if err := validator.Validate(filter); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	scope, err := h.Services.GetTasksScope(context.Background(), user.ID, include)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks lists: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.Tasks.GetUserTasksDueBetween(context.Background(), scope, filter.Assignee, today.AddDate(0, 0, 1), today.AddDate(0, 0, days+1))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachBlocked(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachStatuses(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
+
+func (h ViewsHandler) GetOverdue(w http.ResponseWriter, r *http.Request) {
+	today, err := startOfToday(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.TasksFilter{Assignee: r.URL.Query().Get("assignee")}
+	if err := validator.Validate(filter); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	scope, err := h.Services.GetTasksScope(context.Background(), user.ID, include)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks lists: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.Tasks.GetUserOverdueTasks(context.Background(), scope, filter.Assignee, time.Now(), today)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachBlocked(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachStatuses(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
+
+func (h ViewsHandler) GetStarred(w http.ResponseWriter, r *http.Request) {
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	filter := models.TasksFilter{Assignee: r.URL.Query().Get("assignee")}
+	if err := validator.Validate(filter); err != nil {
+		h.Parent.error(w, fmt.Sprintf("validataion error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	scope, err := h.Services.GetTasksScope(context.Background(), user.ID, include)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks lists: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.Tasks.GetUserStarredTasks(context.Background(), scope, filter.Assignee)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachBlocked(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachStatuses(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
+
+func (h ViewsHandler) GetAssignedToMe(w http.ResponseWriter, r *http.Request) {
+	include, err := models.ParseListsInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Parent.getUser(r)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not get user: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	scope, err := h.Services.GetTasksScope(context.Background(), user.ID, include)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks lists: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.Services.Tasks.GetUserAssignedTasks(context.Background(), scope)
+	if err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find user tasks: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachBlocked(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task dependencies: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Services.AttachStatuses(context.Background(), tasks); err != nil {
+		h.Parent.error(w, fmt.Sprintf("can not find task statuses: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	tasksBytes, _ := json.Marshal(tasks)
+
+	h.Parent.send(w, string(tasksBytes), http.StatusOK)
+}
+
+// startOfToday returns the beginning of the current day in the timezone
+// passed as ?tz=, falling back to UTC.
+func startOfToday(r *http.Request) (time.Time, error) {
+	loc, err := time.LoadLocation(r.URL.Query().Get("tz"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now().In(loc)
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"main/models"
+	"main/utils/logging"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Activity struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewActivityService(db *mongo.Database, logger *logging.Logger) *Activity {
+	// Field values are stored untyped, decode nested documents such as
+	// subtasks as maps so they are served as JSON objects.
+	registry := bson.NewRegistryBuilder().
+		RegisterTypeMapEntry(bsontype.EmbeddedDocument, reflect.TypeOf(bson.M{})).
+		Build()
+	activityCollection := db.Collection("activity", options.Collection().SetRegistry(registry))
+
+	return &Activity{
+		collection: activityCollection,
+		logger:     logger,
+	}
+}
+
+func (s Activity) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "CreatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "CreatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "list_id", Value: 1}, {Key: "CreatedAt", Value: -1}}},
+	})
+
+	return err
+}
+
+// getActivity returns a page of the entries matching query, newest first.
+func (s Activity) getActivity(ctx context.Context, query bson.M, page models.Page) (activity models.ActivityPage, err error) {
+	activity = models.ActivityPage{Activity: []models.Activity{}, Limit: page.Limit, Offset: page.Offset}
+
+	activity.Total, err = s.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return activity, err
+	}
+
+	result, err := s.collection.Find(ctx, query, options.Find().
+		SetSort(bson.D{{Key: "CreatedAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(page.Offset).
+		SetLimit(page.Limit),
+	)
+	if err != nil {
+		return activity, err
+	}
+
+	err = result.All(ctx, &activity.Activity)
+
+	return activity, err
+}
+
+func (s Activity) addActivity(ctx context.Context, activity []interface{}) error {
+	if len(activity) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.InsertMany(ctx, activity)
+
+	return err
+}
+
+func (s Activity) deleteTasksActivity(ctx context.Context, tids []string) error {
+	if len(tids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": tids}})
+
+	return err
+}
+
+// recordActivity records the changes actor made to tasks. before and after
+// are matched by position, see models.NewActivity for how creations and
+// deletions are passed.
+func (s *Services) recordActivity(ctx context.Context, actor string, before []models.Task, after []models.Task) error {
+	var activity []interface{}
+	for i := range before {
+		if entry := models.NewActivity(actor, before[i], after[i]); entry != nil {
+			activity = append(activity, entry)
+		}
+	}
+
+	return s.Activity.addActivity(ctx, activity)
+}
+
+// recordOperation records the activity of a request that changed tasks and
+// logs it as one operation the actor can undo.
+func (s *Services) recordOperation(ctx context.Context, actor string, before []models.Task, after []models.Task) error {
+	if err := s.recordActivity(ctx, actor, before, after); err != nil {
+		return err
+	}
+
+	operation := models.NewOperation(actor, before, after)
+	if operation == nil {
+		return nil
+	}
+
+	return s.Operations.push(ctx, operation)
+}
+
+// GetTaskHistory returns the activity of a task the user can see.
+func (s *Services) GetTaskHistory(ctx context.Context, tid string, uid string, page models.Page) (activity models.ActivityPage, err error) {
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer); err != nil {
+		return activity, err
+	}
+
+	return s.Activity.getActivity(ctx, bson.M{"task_id": tid}, page)
+}
+
+// GetActivityFeed returns the activity of the user's own lists and the
+// lists shared with them.
+func (s *Services) GetActivityFeed(ctx context.Context, uid string, filter models.ActivityFilter, page models.Page) (activity models.ActivityPage, err error) {
+	query := bson.M{}
+
+	if filter.ListID != "" {
+		if _, err := s.AuthorizeTasksList(ctx, filter.ListID, uid, models.RoleViewer); err != nil {
+			return activity, err
+		}
+		query["list_id"] = filter.ListID
+	} else {
+		roles, err := s.Members.getUserRoles(ctx, uid)
+		if err != nil {
+			return activity, err
+		}
+
+		shared := make([]string, 0, len(roles))
+		for tlid := range roles {
+			shared = append(shared, tlid)
+		}
+
+		query["$or"] = bson.A{bson.M{"user_id": uid}, bson.M{"list_id": bson.M{"$in": shared}}}
+	}
+
+	if filter.ActorID != "" {
+		query["actor_id"] = filter.ActorID
+	}
+
+	return s.Activity.getActivity(ctx, query, page)
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"main/models"
+	"main/utils/blobstore"
+	"main/utils/logging"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrAttachmentTooLarge and ErrAttachmentType are returned for uploads
+// outside of the attachment limits. ErrAttachmentNotFound hides whether the
+// attachment or the task is missing, or the user can not see the task.
+var (
+	ErrAttachmentTooLarge = fmt.Errorf("attachment is too large")
+	ErrAttachmentType     = fmt.Errorf("attachment type is not allowed")
+	ErrAttachmentNotFound = fmt.Errorf("attachment not found")
+)
+
+// Attachments keeps the metadata of task attachments in MongoDB and their
+// contents in a blob store. Without a blob store uploads are refused.
+type Attachments struct {
+	collection *mongo.Collection
+	store      blobstore.BlobStore
+	limits     models.AttachmentLimits
+	logger     *logging.Logger
+}
+
+func NewAttachmentsService(db *mongo.Database, logger *logging.Logger) *Attachments {
+	attachmentsCollection := db.Collection("attachments")
+
+	return &Attachments{
+		collection: attachmentsCollection,
+		logger:     logger,
+	}
+}
+
+func (s Attachments) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "CreatedAt", Value: 1}}},
+	})
+
+	return err
+}
+
+// MaxSize is the largest file that can be uploaded.
+func (s Attachments) MaxSize() int64 {
+	return s.limits.MaxSize
+}
+
+func (s Attachments) GetTaskAttachments(ctx context.Context, tid string) (attachments []models.Attachment, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"task_id": tid}, options.Find().SetSort(bson.D{{Key: "CreatedAt", Value: 1}}))
+	if err != nil {
+		return attachments, err
+	}
+
+	err = result.All(ctx, &attachments)
+
+	return attachments, err
+}
+
+func (s Attachments) getTaskAttachment(ctx context.Context, aid string, tid string) (attachment models.Attachment, err error) {
+	aoid, err := primitive.ObjectIDFromHex(aid)
+	if err != nil {
+		return attachment, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": aoid, "task_id": tid}).Decode(&attachment)
+
+	return attachment, err
+}
+
+func (s Attachments) AddAttachment(ctx context.Context, attachment *models.CreateAttachmentDTO) (a models.Attachment, err error) {
+	result, err := s.collection.InsertOne(ctx, attachment)
+	if err != nil {
+		return a, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return a, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *attachment.Build(oid.Hex()), nil
+}
+
+// DeleteAttachment deletes the metadata of an attachment and returns it, so
+// the caller can delete its blob.
+func (s Attachments) DeleteAttachment(ctx context.Context, aid string, tid string) (attachment models.Attachment, err error) {
+	aoid, err := primitive.ObjectIDFromHex(aid)
+	if err != nil {
+		return attachment, err
+	}
+
+	err = s.collection.FindOneAndDelete(ctx, bson.M{"_id": aoid, "task_id": tid}).Decode(&attachment)
+	if err == mongo.ErrNoDocuments {
+		return attachment, fmt.Errorf("attachment not found")
+	}
+
+	return attachment, err
+}
+
+// deleteTasksAttachments deletes the metadata of the attachments of tasks
+// and returns the keys of their blobs.
+func (s Attachments) deleteTasksAttachments(ctx context.Context, tids []string) (keys []string, err error) {
+	if len(tids) == 0 {
+		return keys, nil
+	}
+
+	filter := bson.M{"task_id": bson.M{"$in": tids}}
+
+	result, err := s.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"key": 1}))
+	if err != nil {
+		return keys, err
+	}
+
+	var attachments []models.Attachment
+	if err := result.All(ctx, &attachments); err != nil {
+		return keys, err
+	}
+
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.Key)
+	}
+
+	_, err = s.collection.DeleteMany(ctx, filter)
+
+	return keys, err
+}
+
+// deleteBlobs removes blobs whose metadata is already gone. Failures only
+// leave unreferenced blobs behind, so they are logged and not returned.
+func (s Attachments) deleteBlobs(ctx context.Context, keys []string) {
+	if s.store == nil {
+		return
+	}
+
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.logger.Errorf("can not delete blob %s: %s", key, err.Error())
+		}
+	}
+}
+
+// UseBlobStore stores attachments in store within limits.
+func (s *Services) UseBlobStore(store blobstore.BlobStore, limits models.AttachmentLimits) {
+	s.Attachments.store = store
+	s.Attachments.limits = limits
+}
+
+func (s *Services) GetAttachments(ctx context.Context, tid string, uid string) (attachments []models.Attachment, err error) {
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer); err != nil {
+		return attachments, err
+	}
+
+	return s.Attachments.GetTaskAttachments(ctx, tid)
+}
+
+// AddAttachment uploads a file to a task the user can edit. The type is
+// sniffed from the contents, the name and type sent by the client are not
+// trusted.
+func (s *Services) AddAttachment(ctx context.Context, tid string, uid string, name string, size int64, file io.ReadSeeker) (a models.Attachment, err error) {
+	if s.Attachments.store == nil {
+		return a, fmt.Errorf("attachments storage is not configured")
+	}
+
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return a, err
+	}
+
+	if size > s.Attachments.limits.MaxSize {
+		return a, ErrAttachmentTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return a, err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if !s.Attachments.limits.Allows(contentType) {
+		return a, ErrAttachmentType
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return a, err
+	}
+
+	attachment := &models.CreateAttachmentDTO{
+		TaskID:      tid,
+		UserID:      task.UserID,
+		UploadedBy:  uid,
+		Name:        models.CleanFileName(name),
+		ContentType: contentType,
+		Size:        size,
+		Key:         fmt.Sprintf("%s/%s", tid, uuid.NewString()),
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.Attachments.store.Put(ctx, attachment.Key, file, size, contentType); err != nil {
+		return a, fmt.Errorf("can not store file: %s", err.Error())
+	}
+
+	a, err = s.Attachments.AddAttachment(ctx, attachment)
+	if err != nil {
+		s.Attachments.deleteBlobs(ctx, []string{attachment.Key})
+		return a, err
+	}
+
+	return a, nil
+}
+
+// OpenAttachment returns an attachment of a task the user can see with a
+// reader of its contents, which the caller has to close.
+func (s *Services) OpenAttachment(ctx context.Context, tid string, aid string, uid string) (a models.Attachment, blob io.ReadSeekCloser, err error) {
+	if s.Attachments.store == nil {
+		return a, nil, fmt.Errorf("attachments storage is not configured")
+	}
+
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer); err != nil {
+		return a, nil, ErrAttachmentNotFound
+	}
+
+	a, err = s.Attachments.getTaskAttachment(ctx, aid, tid)
+	if err != nil {
+		return a, nil, ErrAttachmentNotFound
+	}
+
+	blob, err = s.Attachments.store.Open(ctx, a.Key, a.Size)
+	if err == blobstore.ErrNotFound {
+		return a, nil, ErrAttachmentNotFound
+	}
+
+	return a, blob, err
+}
+
+func (s *Services) DeleteAttachment(ctx context.Context, tid string, aid string, uid string) (id string, err error) {
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor); err != nil {
+		return "", err
+	}
+
+	attachment, err := s.Attachments.DeleteAttachment(ctx, aid, tid)
+	if err != nil {
+		return "", err
+	}
+
+	s.Attachments.deleteBlobs(ctx, []string{attachment.Key})
+
+	return attachment.ID, nil
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Comments struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewCommentsService(db *mongo.Database, logger *logging.Logger) *Comments {
+	commentsCollection := db.Collection("comments")
+
+	return &Comments{
+		collection: commentsCollection,
+		logger:     logger,
+	}
+}
+
+func (s Comments) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "CreatedAt", Value: 1}}},
+		{Keys: bson.D{{Key: "mentions", Value: 1}}},
+	})
+
+	return err
+}
+
+// GetTaskComments returns a page of the comments of a task, oldest first.
+func (s Comments) GetTaskComments(ctx context.Context, tid string, page models.Page) (comments models.CommentsPage, err error) {
+	comments = models.CommentsPage{Comments: []models.Comment{}, Limit: page.Limit, Offset: page.Offset}
+
+	comments.Total, err = s.collection.CountDocuments(ctx, bson.M{"task_id": tid})
+	if err != nil {
+		return comments, err
+	}
+
+	result, err := s.collection.Find(ctx, bson.M{"task_id": tid}, options.Find().
+		SetSort(bson.D{{Key: "CreatedAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(page.Offset).
+		SetLimit(page.Limit),
+	)
+	if err != nil {
+		return comments, err
+	}
+
+	err = result.All(ctx, &comments.Comments)
+
+	return comments, err
+}
+
+func (s Comments) getTaskComment(ctx context.Context, cid string, tid string) (comment models.Comment, err error) {
+	coid, err := primitive.ObjectIDFromHex(cid)
+	if err != nil {
+		return comment, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": coid, "task_id": tid}).Decode(&comment)
+
+	return comment, err
+}
+
+func (s Comments) AddComment(ctx context.Context, comment *models.CreateCommentDTO) (c models.Comment, err error) {
+	result, err := s.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return c, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return c, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *comment.Build(oid.Hex()), nil
+}
+
+// UpdateComment replaces the body of a comment and keeps the one it
+// replaces in its history.
+func (s Comments) UpdateComment(ctx context.Context, current models.Comment, body string, mentions []string) (c *models.Comment, err error) {
+	coid, err := primitive.ObjectIDFromHex(current.ID)
+	if err != nil {
+		return c, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": coid},
+		bson.M{
+			"$set":  bson.M{"body": body, "mentions": mentions, "edited_at": time.Now()},
+			"$push": bson.M{"history": current.Revision()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		return c, result.Err()
+	}
+
+	err = result.Decode(&c)
+
+	return c, err
+}
+
+func (s Comments) DeleteComment(ctx context.Context, cid string, tid string) (id string, err error) {
+	coid, err := primitive.ObjectIDFromHex(cid)
+	if err != nil {
+		return cid, err
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": coid, "task_id": tid})
+	if err != nil {
+		return cid, err
+	}
+
+	if result.DeletedCount == 0 {
+		return "", fmt.Errorf("comment not found")
+	}
+
+	return cid, nil
+}
+
+func (s Comments) deleteTasksComments(ctx context.Context, tids []string) error {
+	if len(tids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": tids}})
+
+	return err
+}
+
+func (s *Services) GetTaskComments(ctx context.Context, tid string, uid string, page models.Page) (comments models.CommentsPage, err error) {
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer); err != nil {
+		return comments, err
+	}
+
+	return s.Comments.GetTaskComments(ctx, tid, page)
+}
+
+// AddComment posts a comment on a task the user can edit and counts it on
+// the task.
+func (s *Services) AddComment(ctx context.Context, tid string, uid string, rb *models.CreateCommentRB) (c models.Comment, err error) {
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return c, err
+	}
+
+	comment := rb.Build(tid, uid)
+	comment.Mentions, err = s.resolveMentions(ctx, task.ListID, comment.Body)
+	if err != nil {
+		return c, fmt.Errorf("can not resolve mentions: %s", err.Error())
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		c, err = s.Comments.AddComment(ctx, comment)
+		if err != nil {
+			return err
+		}
+
+		return s.Tasks.incCommentsCount(ctx, tid, 1)
+	})
+
+	return c, err
+}
+
+// UpdateComment lets authors edit their own comments while they still have
+// access to the task.
+func (s *Services) UpdateComment(ctx context.Context, tid string, cid string, uid string, rb *models.UpdateCommentRB) (c *models.Comment, err error) {
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer)
+	if err != nil {
+		return c, err
+	}
+
+	current, err := s.Comments.getTaskComment(ctx, cid, tid)
+	if err != nil {
+		return c, fmt.Errorf("comment not found")
+	}
+
+	if current.AuthorID != uid {
+		return c, fmt.Errorf("permission denied: only the author can edit a comment")
+	}
+
+	mentions, err := s.resolveMentions(ctx, task.ListID, rb.Body)
+	if err != nil {
+		return c, fmt.Errorf("can not resolve mentions: %s", err.Error())
+	}
+
+	return s.Comments.UpdateComment(ctx, current, rb.Body, mentions)
+}
+
+// DeleteComment deletes a comment of its author or, for moderation, any
+// comment on a list the user owns.
+func (s *Services) DeleteComment(ctx context.Context, tid string, cid string, uid string) (id string, err error) {
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := s.Comments.getTaskComment(ctx, cid, tid)
+	if err != nil {
+		return "", fmt.Errorf("comment not found")
+	}
+
+	if current.AuthorID != uid && task.UserID != uid {
+		if _, err := s.AuthorizeTasksList(ctx, task.ListID, uid, models.RoleOwner); err != nil {
+			return "", fmt.Errorf("permission denied: only the author or an owner can delete a comment")
+		}
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		id, err = s.Comments.DeleteComment(ctx, cid, tid)
+		if err != nil {
+			return err
+		}
+
+		return s.Tasks.incCommentsCount(ctx, tid, -1)
+	})
+
+	return id, err
+}
+
+// resolveMentions maps the emails mentioned in body to the ids of the users
+// with access to the list. Mentions of anyone else are left as plain text.
+func (s *Services) resolveMentions(ctx context.Context, tlid string, body string) (uids []string, err error) {
+	uids = []string{}
+
+	emails := models.ParseMentions(body)
+	if len(emails) == 0 {
+		return uids, nil
+	}
+
+	tasksList, err := s.TasksLists.getTasksList(ctx, tlid)
+	if err != nil {
+		return uids, err
+	}
+
+	owner, err := s.Users.FindUserByID(ctx, tasksList.UserID)
+	if err != nil {
+		return uids, err
+	}
+
+	members, err := s.Members.GetListMembers(ctx, tlid)
+	if err != nil {
+		return uids, err
+	}
+
+	access := map[string]string{strings.ToLower(owner.Email): owner.ID}
+	for _, member := range members {
+		if member.Status == models.MemberAccepted && member.UserID != "" {
+			access[member.Email] = member.UserID
+		}
+	}
+
+	for _, email := range emails {
+		if uid, ok := access[email]; ok {
+			uids = append(uids, uid)
+		}
+	}
+
+	return uids, nil
+}
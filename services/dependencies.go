@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrDependencyCycle is returned for a dependency that would make a task
+// wait, directly or not, for itself.
+var ErrDependencyCycle = fmt.Errorf("dependency cycle")
+
+type Dependencies struct {
+	collection *mongo.Collection
+	locks      *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewDependenciesService(db *mongo.Database, logger *logging.Logger) *Dependencies {
+	dependenciesCollection := db.Collection("dependencies")
+
+	return &Dependencies{
+		collection: dependenciesCollection,
+		locks:      db.Collection("locks"),
+		logger:     logger,
+	}
+}
+
+func (s Dependencies) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "blocker_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "blocker_id", Value: 1}}},
+	})
+
+	return err
+}
+
+func (s Dependencies) find(ctx context.Context, query bson.M) (dependencies []models.Dependency, err error) {
+	result, err := s.collection.Find(ctx, query)
+	if err != nil {
+		return dependencies, err
+	}
+
+	err = result.All(ctx, &dependencies)
+
+	return dependencies, err
+}
+
+// getBlockers returns the dependencies of the tasks on other tasks.
+func (s Dependencies) getBlockers(ctx context.Context, tids []string) ([]models.Dependency, error) {
+	if len(tids) == 0 {
+		return nil, nil
+	}
+
+	return s.find(ctx, bson.M{"task_id": bson.M{"$in": tids}})
+}
+
+// getDependents returns the dependencies of other tasks on the task.
+func (s Dependencies) getDependents(ctx context.Context, tid string) ([]models.Dependency, error) {
+	return s.find(ctx, bson.M{"blocker_id": tid})
+}
+
+// lockOwners writes the dependency lock of every owner, in sorted order,
+// so transactions adding dependencies between tasks of the same owners
+// conflict with each other and one of them is retried.
+func (s Dependencies) lockOwners(ctx context.Context, uids []string) error {
+	sorted := append([]string(nil), uids...)
+	sort.Strings(sorted)
+
+	for i, uid := range sorted {
+		if i > 0 && uid == sorted[i-1] {
+			continue
+		}
+
+		_, err := s.locks.UpdateOne(ctx,
+			bson.M{"_id": fmt.Sprintf("dependencies:%s", uid)},
+			bson.M{"$inc": bson.M{"version": 1}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s Dependencies) addDependency(ctx context.Context, dependency *models.CreateDependencyDTO) (d models.Dependency, err error) {
+	result, err := s.collection.InsertOne(ctx, dependency)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return d, fmt.Errorf("task already depends on %s", dependency.BlockerID)
+		}
+		return d, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return d, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *dependency.Build(oid.Hex()), nil
+}
+
+func (s Dependencies) deleteDependency(ctx context.Context, tid string, bid string) (id string, err error) {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"task_id": tid, "blocker_id": bid})
+	if err != nil {
+		return bid, err
+	}
+
+	if result.DeletedCount == 0 {
+		return "", fmt.Errorf("dependency not found")
+	}
+
+	return bid, nil
+}
+
+func (s Dependencies) deleteTasksDependencies(ctx context.Context, tids []string) error {
+	if len(tids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"task_id": bson.M{"$in": tids}},
+		bson.M{"blocker_id": bson.M{"$in": tids}},
+	}})
+
+	return err
+}
+
+// GetTaskDependencies returns the tasks a task the user can see waits for
+// and the ones waiting for it.
+func (s *Services) GetTaskDependencies(ctx context.Context, tid string, uid string) (dependencies models.TaskDependencies, err error) {
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleViewer); err != nil {
+		return dependencies, err
+	}
+
+	blockers, err := s.Dependencies.getBlockers(ctx, []string{tid})
+	if err != nil {
+		return dependencies, err
+	}
+
+	dependents, err := s.Dependencies.getDependents(ctx, tid)
+	if err != nil {
+		return dependencies, err
+	}
+
+	scope, err := s.GetTasksScope(ctx, uid, models.ListsInclude{Archived: true, Hidden: true})
+	if err != nil {
+		return dependencies, err
+	}
+
+	bids := make([]string, len(blockers))
+	for i, dependency := range blockers {
+		bids[i] = dependency.BlockerID
+	}
+
+	dids := make([]string, len(dependents))
+	for i, dependency := range dependents {
+		dids[i] = dependency.TaskID
+	}
+
+	dependencies = models.TaskDependencies{TaskID: tid}
+
+	open, err := s.Tasks.getOpenTaskIDs(ctx, bids)
+	if err != nil {
+		return dependencies, err
+	}
+	dependencies.IsBlocked = len(open) > 0
+
+	dependencies.BlockedBy, err = s.Tasks.getScopedTasksByIDs(ctx, scope, bids)
+	if err != nil {
+		return dependencies, err
+	}
+
+	dependencies.Blocks, err = s.Tasks.getScopedTasksByIDs(ctx, scope, dids)
+	if err != nil {
+		return dependencies, err
+	}
+
+	if err := s.AttachBlocked(ctx, dependencies.BlockedBy); err != nil {
+		return dependencies, err
+	}
+
+	if err := s.AttachBlocked(ctx, dependencies.Blocks); err != nil {
+		return dependencies, err
+	}
+
+	if err := s.AttachStatuses(ctx, dependencies.BlockedBy); err != nil {
+		return dependencies, err
+	}
+
+	return dependencies, s.AttachStatuses(ctx, dependencies.Blocks)
+}
+
+// AddDependency makes a task the user can edit wait for another task the
+// user can see. Tasks of different owners can only depend on each other
+// when one owner collaborates on the list of the other's task.
+func (s *Services) AddDependency(ctx context.Context, tid string, uid string, rb *models.CreateDependencyRB) (d models.Dependency, err error) {
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return d, err
+	}
+
+	blocker, err := s.AuthorizeTask(ctx, rb.BlockerID, uid, models.RoleViewer)
+	if err != nil {
+		return d, fmt.Errorf("can not find blocker: %s", err.Error())
+	}
+
+	if err := s.checkLinkable(ctx, task, blocker); err != nil {
+		return d, err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		cycle, visited, err := s.dependsOn(ctx, blocker.ID, task.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("%w: %s already depends on %s", ErrDependencyCycle, blocker.ID, task.ID)
+		}
+
+		// Two transactions could each find no cycle and together close one.
+		// The cycle search of each of them then reaches the task the other
+		// one links, so locking the owners of every task it visited makes
+		// them conflict.
+		owners, err := s.Tasks.getTaskOwners(ctx, visited)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Dependencies.lockOwners(ctx, append(owners, task.UserID, blocker.UserID)); err != nil {
+			return err
+		}
+
+		d, err = s.Dependencies.addDependency(ctx, rb.Build(task.ID, uid))
+
+		return err
+	})
+
+	return d, err
+}
+
+// RemoveDependency stops a task the user can edit from waiting for bid.
+func (s *Services) RemoveDependency(ctx context.Context, tid string, bid string, uid string) (id string, err error) {
+	if _, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor); err != nil {
+		return id, err
+	}
+
+	return s.Dependencies.deleteDependency(ctx, tid, bid)
+}
+
+// AttachBlocked sets IsBlocked on every task that waits for a task which is
+// neither complete nor in the trash.
+func (s *Services) AttachBlocked(ctx context.Context, tasks []models.Task) error {
+	tids := make([]string, len(tasks))
+	for i, task := range tasks {
+		tids[i] = task.ID
+	}
+
+	blockers, err := s.Dependencies.getBlockers(ctx, tids)
+	if err != nil {
+		return err
+	}
+
+	bids := make([]string, len(blockers))
+	for i, dependency := range blockers {
+		bids[i] = dependency.BlockerID
+	}
+
+	open, err := s.Tasks.getOpenTaskIDs(ctx, bids)
+	if err != nil {
+		return err
+	}
+
+	blocked := map[string]bool{}
+	for _, dependency := range blockers {
+		if open[dependency.BlockerID] {
+			blocked[dependency.TaskID] = true
+		}
+	}
+
+	for i := range tasks {
+		tasks[i].IsBlocked = blocked[tasks[i].ID]
+	}
+
+	return nil
+}
+
+// checkLinkable allows dependencies between tasks of the same owner, or of
+// owners where one collaborates on the list of the other's task.
+func (s *Services) checkLinkable(ctx context.Context, task models.Task, blocker models.Task) error {
+	if task.UserID == blocker.UserID {
+		return nil
+	}
+
+	role, err := s.Members.getRole(ctx, blocker.ListID, task.UserID)
+	if err != nil || role != "" {
+		return err
+	}
+
+	role, err = s.Members.getRole(ctx, task.ListID, blocker.UserID)
+	if err != nil || role != "" {
+		return err
+	}
+
+	return fmt.Errorf("permission denied: tasks of unrelated owners can not depend on each other")
+}
+
+// dependsOn reports whether tid waits for bid, directly or through other
+// tasks, and returns the tasks it went through.
+func (s *Services) dependsOn(ctx context.Context, tid string, bid string) (bool, []string, error) {
+	visited := []string{tid}
+	if tid == bid {
+		return true, visited, nil
+	}
+
+	seen := map[string]bool{tid: true}
+	next := []string{tid}
+
+	for len(next) > 0 {
+		blockers, err := s.Dependencies.getBlockers(ctx, next)
+		if err != nil {
+			return false, visited, err
+		}
+
+		next = nil
+		for _, dependency := range blockers {
+			if dependency.BlockerID == bid {
+				return true, visited, nil
+			}
+			if !seen[dependency.BlockerID] {
+				seen[dependency.BlockerID] = true
+				next = append(next, dependency.BlockerID)
+				visited = append(visited, dependency.BlockerID)
+			}
+		}
+	}
+
+	return false, visited, nil
+}
+
+// notifyUnblocked schedules an immediate reminder for the owners of the
+// tasks that asked to be notified and no longer wait for anything now that
+// blocker is complete.
+func (s *Services) notifyUnblocked(ctx context.Context, blocker models.Task) error {
+	dependents, err := s.Dependencies.getDependents(ctx, blocker.ID)
+	if err != nil {
+		return err
+	}
+
+	var dids []string
+	for _, dependency := range dependents {
+		if dependency.Notify {
+			dids = append(dids, dependency.TaskID)
+		}
+	}
+
+	if len(dids) == 0 {
+		return nil
+	}
+
+	blockers, err := s.Dependencies.getBlockers(ctx, dids)
+	if err != nil {
+		return err
+	}
+
+	bids := make([]string, len(blockers))
+	for i, dependency := range blockers {
+		bids[i] = dependency.BlockerID
+	}
+
+	open, err := s.Tasks.getOpenTaskIDs(ctx, append(bids, dids...))
+	if err != nil {
+		return err
+	}
+
+	blocked := map[string]bool{}
+	for _, dependency := range blockers {
+		if open[dependency.BlockerID] {
+			blocked[dependency.TaskID] = true
+		}
+	}
+
+	now := time.Now()
+	notified := map[string]bool{}
+	for _, did := range dids {
+		if !open[did] || blocked[did] || notified[did] {
+			continue
+		}
+		notified[did] = true
+
+		task, err := s.Tasks.getTask(ctx, did)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Reminders.AddReminder(ctx, &models.CreateReminderDTO{
+			UserID:    task.UserID,
+			TaskID:    task.ID,
+			Kind:      models.ReminderUnblocked,
+			At:        &now,
+			FireAt:    &now,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
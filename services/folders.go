@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Folders struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewFoldersService(db *mongo.Database, logger *logging.Logger) *Folders {
+	foldersCollection := db.Collection("folders")
+
+	return &Folders{
+		collection: foldersCollection,
+		logger:     logger,
+	}
+}
+
+func (s Folders) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "position", Value: 1}},
+	})
+
+	return err
+}
+
+func (s Folders) GetAllUserFolders(ctx context.Context, uid string) (folders []models.Folder, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid}, options.Find().SetSort(positionOrder))
+	if err != nil {
+		return folders, err
+	}
+
+	err = result.All(ctx, &folders)
+
+	return folders, err
+}
+
+func (s Folders) GetUserFolder(ctx context.Context, fid string, uid string) (folder models.Folder, err error) {
+	foid, err := primitive.ObjectIDFromHex(fid)
+	if err != nil {
+		return folder, err
+	}
+
+	result := s.collection.FindOne(ctx, bson.M{"_id": foid, "user_id": uid})
+	if result.Err() != nil {
+		return folder, result.Err()
+	}
+
+	err = result.Decode(&folder)
+
+	return folder, err
+}
+
+func (s Folders) AddFolder(ctx context.Context, folder *models.CreateFolderDTO) (f models.Folder, err error) {
+	folder.Position, err = nextPosition(ctx, s.collection, bson.M{"user_id": folder.UserID})
+	if err != nil {
+		return f, err
+	}
+
+	result, err := s.collection.InsertOne(ctx, folder)
+	if err != nil {
+		return f, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return f, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *folder.Build(oid.Hex()), nil
+}
+
+func (s Folders) UpdateFolder(ctx context.Context, fid string, uid string, folder *models.UpdateFolderDTO) (f *models.Folder, err error) {
+	foid, err := primitive.ObjectIDFromHex(fid)
+	if err != nil {
+		return f, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": foid, "user_id": uid}, bson.M{"$set": folder},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		return f, result.Err()
+	}
+
+	err = result.Decode(&f)
+
+	return f, err
+}
+
+// MoveFolder places the folder between two neighbours among the user's
+// folders.
+func (s Folders) MoveFolder(ctx context.Context, fid string, uid string, move *models.MoveRB) (f *models.Folder, err error) {
+	if _, err := s.GetUserFolder(ctx, fid, uid); err != nil {
+		return f, err
+	}
+
+	foid, _ := primitive.ObjectIDFromHex(fid)
+
+	err = movePosition(ctx, s.collection, foid, bson.M{"user_id": uid}, move.After, move.Before)
+	if err != nil {
+		return f, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": foid}).Decode(&f)
+
+	return f, err
+}
+
+// DeleteFolder removes the folder and moves its lists to the root level in
+// one transaction.
+func (s *Services) DeleteFolder(ctx context.Context, fid string, uid string) (id string, err error) {
+	foid, err := primitive.ObjectIDFromHex(fid)
+	if err != nil {
+		return fid, err
+	}
+
+	err = s.Transaction(ctx, func(sc context.Context) error {
+		result, err := s.Folders.collection.DeleteOne(sc, bson.M{"_id": foid, "user_id": uid})
+		if err != nil {
+			return err
+		}
+
+		if result.DeletedCount == 0 {
+			return fmt.Errorf("folder not found")
+		}
+
+		return s.TasksLists.clearFolder(sc, fid, uid)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fid, nil
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Members struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewMembersService(db *mongo.Database, logger *logging.Logger) *Members {
+	membersCollection := db.Collection("members")
+
+	return &Members{
+		collection: membersCollection,
+		logger:     logger,
+	}
+}
+
+func (s Members) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "list_id", Value: 1}, {Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "status", Value: 1}}},
+	})
+
+	return err
+}
+
+func (s Members) GetListMembers(ctx context.Context, tlid string) (members []models.Member, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"list_id": tlid}, options.Find().SetSort(bson.D{{Key: "CreatedAt", Value: 1}}))
+	if err != nil {
+		return members, err
+	}
+
+	err = result.All(ctx, &members)
+
+	return members, err
+}
+
+func (s Members) GetUserInvitations(ctx context.Context, email string) (members []models.Member, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"email": strings.ToLower(email), "status": models.MemberPending},
+		options.Find().SetSort(bson.D{{Key: "CreatedAt", Value: -1}}))
+	if err != nil {
+		return members, err
+	}
+
+	err = result.All(ctx, &members)
+
+	return members, err
+}
+
+// AddMember stores an invitation. A declined invitation of the same email
+// is replaced, so the list can invite them again.
+func (s Members) AddMember(ctx context.Context, member *models.InviteMemberDTO) (m models.Member, err error) {
+	_, err = s.collection.DeleteOne(ctx, bson.M{"list_id": member.ListID, "email": member.Email, "status": models.MemberDeclined})
+	if err != nil {
+		return m, err
+	}
+
+	result, err := s.collection.InsertOne(ctx, member)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return m, fmt.Errorf("%s is already invited", member.Email)
+		}
+		return m, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return m, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *member.Build(oid.Hex()), nil
+}
+
+func (s Members) getListMember(ctx context.Context, mid string, tlid string) (member models.Member, err error) {
+	moid, err := primitive.ObjectIDFromHex(mid)
+	if err != nil {
+		return member, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": moid, "list_id": tlid}).Decode(&member)
+
+	return member, err
+}
+
+// RespondInvitation accepts or declines a pending invitation sent to the
+// user's email.
+func (s Members) RespondInvitation(ctx context.Context, mid string, user models.User, accept bool) (m *models.Member, err error) {
+	moid, err := primitive.ObjectIDFromHex(mid)
+	if err != nil {
+		return m, err
+	}
+
+	update := bson.M{"status": models.MemberDeclined, "UpdatedAt": time.Now()}
+	if accept {
+		update["status"] = models.MemberAccepted
+		update["user_id"] = user.ID
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": moid, "email": strings.ToLower(user.Email), "status": models.MemberPending},
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		return m, result.Err()
+	}
+
+	err = result.Decode(&m)
+
+	return m, err
+}
+
+func (s Members) UpdateMemberRole(ctx context.Context, mid string, tlid string, role string) (m *models.Member, err error) {
+	moid, err := primitive.ObjectIDFromHex(mid)
+	if err != nil {
+		return m, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": moid, "list_id": tlid},
+		bson.M{"$set": bson.M{"role": role, "UpdatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		return m, result.Err()
+	}
+
+	err = result.Decode(&m)
+
+	return m, err
+}
+
+func (s Members) RemoveMember(ctx context.Context, mid string, tlid string) (id string, err error) {
+	moid, err := primitive.ObjectIDFromHex(mid)
+	if err != nil {
+		return mid, err
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": moid, "list_id": tlid})
+	if err != nil {
+		return mid, err
+	}
+
+	if result.DeletedCount == 0 {
+		return "", fmt.Errorf("member not found")
+	}
+
+	return mid, nil
+}
+
+// getRole returns the role of an accepted member, or an empty string when
+// the user is not a member of the list.
+func (s Members) getRole(ctx context.Context, tlid string, uid string) (string, error) {
+	var member models.Member
+
+	err := s.collection.FindOne(ctx, bson.M{"list_id": tlid, "user_id": uid, "status": models.MemberAccepted}).Decode(&member)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+
+	return member.Role, err
+}
+
+// getUserRoles maps the lists shared with the user to their role.
+func (s Members) getUserRoles(ctx context.Context, uid string) (map[string]string, error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid, "status": models.MemberAccepted})
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.Member
+	if err := result.All(ctx, &members); err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]string, len(members))
+	for _, member := range members {
+		roles[member.ListID] = member.Role
+	}
+
+	return roles, nil
+}
+
+// getListsMemberIDs returns the users who accepted to collaborate on any of
+// the lists.
+func (s Members) getListsMemberIDs(ctx context.Context, tlids []string) ([]string, error) {
+	if len(tlids) == 0 {
+		return nil, nil
+	}
+
+	uids, err := s.collection.Distinct(ctx, "user_id", bson.M{"list_id": bson.M{"$in": tlids}, "status": models.MemberAccepted})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if id, ok := uid.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (s Members) deleteListsMembers(ctx context.Context, tlids []string) error {
+	if len(tlids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"list_id": bson.M{"$in": tlids}})
+
+	return err
+}
+
+// AuthorizeTasksList returns the list when the user has at least role on
+// it. The user who created the list is its owner. Lists the user can not
+// see at all are reported as not found.
+func (s *Services) AuthorizeTasksList(ctx context.Context, tlid string, uid string, role string) (tasksList models.TasksList, err error) {
+	tasksList, err = s.TasksLists.getTasksList(ctx, tlid)
+	if err != nil {
+		return tasksList, fmt.Errorf("tasks list not found")
+	}
+
+	if tasksList.UserID == uid {
+		tasksList.Role = models.RoleOwner
+		return tasksList, nil
+	}
+
+	tasksList.Role, err = s.Members.getRole(ctx, tlid, uid)
+	if err != nil {
+		return tasksList, err
+	}
+
+	if tasksList.Role == "" {
+		return tasksList, fmt.Errorf("tasks list not found")
+	}
+
+	if !models.RoleAllows(tasksList.Role, role) {
+		return tasksList, fmt.Errorf("permission denied: %s role required", role)
+	}
+
+	return tasksList, nil
+}
+
+// AuthorizeTask returns the task when the user has at least role on its
+// list. Callers act on the task with task.UserID, the owner of its list.
+func (s *Services) AuthorizeTask(ctx context.Context, tid string, uid string, role string) (task models.Task, err error) {
+	task, err = s.Tasks.getTask(ctx, tid)
+	if err != nil {
+		return task, fmt.Errorf("task not found")
+	}
+
+	if task.UserID == uid {
+		return task, nil
+	}
+
+	if _, err := s.AuthorizeTasksList(ctx, task.ListID, uid, role); err != nil {
+		if strings.HasPrefix(err.Error(), "permission denied") {
+			return task, err
+		}
+		return task, fmt.Errorf("task not found")
+	}
+
+	return task, nil
+}
+
+// GetAllTasksLists returns the user's own lists and the lists shared with
+// them, each with the user's role.
+func (s *Services) GetAllTasksLists(ctx context.Context, uid string, include models.ListsInclude) (tasksLists []models.TasksList, err error) {
+	roles, err := s.Members.getUserRoles(ctx, uid)
+	if err != nil {
+		return tasksLists, err
+	}
+
+	shared := make([]string, 0, len(roles))
+	for tlid := range roles {
+		shared = append(shared, tlid)
+	}
+
+	tasksLists, err = s.TasksLists.GetAllUserTasksLists(ctx, uid, shared, include)
+	if err != nil {
+		return tasksLists, err
+	}
+
+	for i := range tasksLists {
+		if tasksLists[i].UserID == uid {
+			tasksLists[i].Role = models.RoleOwner
+		} else {
+			tasksLists[i].Role = roles[tasksLists[i].ID]
+		}
+	}
+
+	return tasksLists, nil
+}
+
+// GetTasksScope returns what task listings of the user cover.
+func (s *Services) GetTasksScope(ctx context.Context, uid string, include models.ListsInclude) (scope models.TasksScope, err error) {
+	scope.UserID = uid
+
+	roles, err := s.Members.getUserRoles(ctx, uid)
+	if err != nil {
+		return scope, err
+	}
+
+	for tlid := range roles {
+		scope.SharedListIDs = append(scope.SharedListIDs, tlid)
+	}
+
+	if include.Archived {
+		return scope, nil
+	}
+
+	scope.ExcludeListIDs, err = s.TasksLists.getArchivedListIDs(ctx, uid, scope.SharedListIDs)
+
+	return scope, err
+}
+
+// InviteMember invites a user by email. Only owners can invite.
+func (s *Services) InviteMember(ctx context.Context, tlid string, uid string, invite *models.InviteMemberRB) (m models.Member, err error) {
+	tasksList, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner)
+	if err != nil {
+		return m, err
+	}
+
+	if tasksList.Inbox {
+		return m, fmt.Errorf("inbox can not be shared")
+	}
+
+	owner, err := s.Users.FindUserByID(ctx, tasksList.UserID)
+	if err != nil {
+		return m, err
+	}
+
+	member := invite.Build(tasksList, uid)
+	if member.Email == strings.ToLower(owner.Email) {
+		return m, fmt.Errorf("the owner can not be invited")
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		m, err = s.Members.AddMember(ctx, member)
+		return err
+	})
+
+	return m, err
+}
+
+// RespondInvitation accepts or declines an invitation sent to the user. The
+// counts the user has cached do not cover a list they just joined.
+func (s *Services) RespondInvitation(ctx context.Context, mid string, user models.User, accept bool) (m *models.Member, err error) {
+	m, err = s.Members.RespondInvitation(ctx, mid, user, accept)
+	if err != nil {
+		return m, err
+	}
+
+	if accept {
+		s.stats.invalidate(ctx, user.ID)
+	}
+
+	return m, nil
+}
+
+func (s *Services) GetTasksListMembers(ctx context.Context, tlid string, uid string) (members []models.Member, err error) {
+	if _, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleViewer); err != nil {
+		return members, err
+	}
+
+	return s.Members.GetListMembers(ctx, tlid)
+}
+
+func (s *Services) UpdateMemberRole(ctx context.Context, tlid string, mid string, uid string, role string) (m *models.Member, err error) {
+	if _, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner); err != nil {
+		return m, err
+	}
+
+	return s.Members.UpdateMemberRole(ctx, mid, tlid, role)
+}
+
+// RemoveMember removes a member or revokes an invitation. Owners can remove
+// anyone, other members only themselves. Tasks of the list assigned to the
+// member are unassigned.
+func (s *Services) RemoveMember(ctx context.Context, tlid string, mid string, uid string) (id string, err error) {
+	tasksList, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleViewer)
+	if err != nil {
+		return "", err
+	}
+
+	member, err := s.Members.getListMember(ctx, mid, tlid)
+	if err != nil && tasksList.Role == models.RoleOwner {
+		return "", fmt.Errorf("member not found")
+	}
+
+	if tasksList.Role != models.RoleOwner && (err != nil || member.UserID != uid) {
+		return "", fmt.Errorf("permission denied: %s role required", models.RoleOwner)
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		id, err = s.Members.RemoveMember(ctx, mid, tlid)
+		if err != nil {
+			return err
+		}
+
+		if member.UserID == "" {
+			return nil
+		}
+
+		// The counts the removed user has cached still cover the list.
+		s.afterCommit(ctx, func() {
+			s.stats.invalidate(context.Background(), member.UserID)
+		})
+
+		return s.Tasks.unassignListMember(ctx, tlid, member.UserID)
+	})
+
+	return id, err
+}
+
+// checkAssignee makes sure tasks of the list can be assigned to the user,
+// which must be its owner or one of its accepted members.
+func (s *Services) checkAssignee(ctx context.Context, tasksList models.TasksList, assignee string) error {
+	if assignee == "" || assignee == tasksList.UserID {
+		return nil
+	}
+
+	role, err := s.Members.getRole(ctx, tasksList.ID, assignee)
+	if err != nil {
+		return err
+	}
+
+	if role == "" {
+		return fmt.Errorf("assignee is not a member of the tasks list")
+	}
+
+	return nil
+}
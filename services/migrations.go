@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type migration struct {
+	Name string
+	Run  func(ctx context.Context, s *Services) error
+}
+
+// migrations are applied once, in order. Append new entries at the end and
+// never rename or remove applied ones.
+var migrations = []migration{
+	{
+		Name: "subtask-ids",
+		Run: func(ctx context.Context, s *Services) error {
+			return s.Tasks.backfillSubIDs(ctx)
+		},
+	},
+	{
+		Name: "positions",
+		Run: func(ctx context.Context, s *Services) error {
+			if err := s.TasksLists.backfillPositions(ctx); err != nil {
+				return err
+			}
+			return s.Tasks.backfillPositions(ctx)
+		},
+	},
+	{
+		Name: "inbox",
+		Run: func(ctx context.Context, s *Services) error {
+			users, err := s.Users.GetAllUsers(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, user := range users {
+				if _, err := s.TasksLists.EnsureInbox(ctx, user.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+}
+
+// Migrate applies every migration that is not recorded in the migrations
+// collection yet.
+func (s *Services) Migrate(ctx context.Context) error {
+	for _, m := range migrations {
+		err := s.migrations.FindOne(ctx, bson.M{"_id": m.Name}).Err()
+		if err == nil {
+			continue
+		}
+		if err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		s.logger.Infof("Apply migration %s", m.Name)
+
+		if err := m.Run(ctx, s); err != nil {
+			return err
+		}
+
+		_, err = s.migrations.InsertOne(ctx, bson.M{"_id": m.Name, "applied_at": time.Now()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultUndoWindow = 10 * time.Minute
+	// operationsRetention bounds how long the undo log is kept, it only
+	// has to outlive the longest undo window.
+	operationsRetention = 24 * time.Hour
+)
+
+// ErrNothingToUndo, ErrNothingToRedo and ErrUndoConflict are returned by
+// Undo and Redo. Conflicts wrap ErrUndoConflict with the task that changed.
+var (
+	ErrNothingToUndo = fmt.Errorf("nothing to undo")
+	ErrNothingToRedo = fmt.Errorf("nothing to redo")
+	ErrUndoConflict  = fmt.Errorf("undo conflict")
+)
+
+// Operations is the undo log. Every user has a stack of done operations
+// followed by the ones they undid, a new operation drops the undone ones.
+type Operations struct {
+	collection *mongo.Collection
+	window     time.Duration
+	logger     *logging.Logger
+}
+
+func NewOperationsService(db *mongo.Database, logger *logging.Logger) *Operations {
+	operationsCollection := db.Collection("operations")
+
+	return &Operations{
+		collection: operationsCollection,
+		window:     defaultUndoWindow,
+		logger:     logger,
+	}
+}
+
+func (s Operations) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "state", Value: 1}, {Key: "CreatedAt", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "CreatedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(operationsRetention.Seconds())),
+		},
+	})
+
+	return err
+}
+
+func (s Operations) push(ctx context.Context, operation *models.Operation) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"user_id": operation.UserID, "state": models.OperationUndone})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.InsertOne(ctx, operation)
+
+	return err
+}
+
+// lastDone returns the latest operation of the user that can be undone.
+func (s Operations) lastDone(ctx context.Context, uid string) (operation models.Operation, err error) {
+	err = s.collection.FindOne(ctx, bson.M{
+		"user_id":   uid,
+		"state":     models.OperationDone,
+		"CreatedAt": bson.M{"$gte": time.Now().Add(-s.window)},
+	}, options.FindOne().SetSort(bson.D{{Key: "CreatedAt", Value: -1}, {Key: "_id", Value: -1}})).Decode(&operation)
+
+	return operation, err
+}
+
+// firstUndone returns the operation the user undid last, which is the
+// oldest of the undone ones.
+func (s Operations) firstUndone(ctx context.Context, uid string) (operation models.Operation, err error) {
+	err = s.collection.FindOne(ctx, bson.M{
+		"user_id":   uid,
+		"state":     models.OperationUndone,
+		"undone_at": bson.M{"$gte": time.Now().Add(-s.window)},
+	}, options.FindOne().SetSort(bson.D{{Key: "CreatedAt", Value: 1}, {Key: "_id", Value: 1}})).Decode(&operation)
+
+	return operation, err
+}
+
+func (s Operations) setState(ctx context.Context, opid string, state string) error {
+	ooid, err := primitive.ObjectIDFromHex(opid)
+	if err != nil {
+		return err
+	}
+
+	var undoneAt *time.Time
+	if state == models.OperationUndone {
+		now := time.Now()
+		undoneAt = &now
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": ooid}, bson.M{"$set": bson.M{"state": state, "undone_at": undoneAt}})
+
+	return err
+}
+
+// UseUndoWindow sets how long operations can be undone and redone.
+func (s *Services) UseUndoWindow(window time.Duration) {
+	if window > 0 {
+		s.Operations.window = window
+	}
+}
+
+// Undo reverts up to steps of the user's latest operations, newest first.
+// Either all of them are reverted or, when a task has changed since, none.
+func (s *Services) Undo(ctx context.Context, uid string, steps int) (result models.UndoResult, err error) {
+	return s.replay(ctx, uid, steps, true)
+}
+
+// Redo reapplies up to steps of the operations the user undid, in the
+// order they were undone.
+func (s *Services) Redo(ctx context.Context, uid string, steps int) (result models.UndoResult, err error) {
+	return s.replay(ctx, uid, steps, false)
+}
+
+func (s *Services) replay(ctx context.Context, uid string, steps int, undo bool) (result models.UndoResult, err error) {
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		result = models.UndoResult{Operations: []string{}, Tasks: []models.Task{}}
+
+		for i := 0; i < steps; i++ {
+			var operation models.Operation
+			var err error
+			if undo {
+				operation, err = s.Operations.lastDone(ctx, uid)
+			} else {
+				operation, err = s.Operations.firstUndone(ctx, uid)
+			}
+			if err == mongo.ErrNoDocuments && i > 0 {
+				break
+			}
+			if err == mongo.ErrNoDocuments && undo {
+				return ErrNothingToUndo
+			}
+			if err == mongo.ErrNoDocuments {
+				return ErrNothingToRedo
+			}
+			if err != nil {
+				return err
+			}
+
+			state := models.OperationDone
+			if undo {
+				state = models.OperationUndone
+			}
+
+			for j := range operation.Changes {
+				change := operation.Changes[j]
+				if undo {
+					change = operation.Changes[len(operation.Changes)-1-j]
+				}
+
+				from, to := change.Before, change.After
+				if undo {
+					from, to = to, from
+				}
+
+				task, err := s.revertTask(ctx, uid, change.TaskID, from, to)
+				if err != nil {
+					return err
+				}
+				result.Tasks = append(result.Tasks, task)
+			}
+
+			if err := s.Operations.setState(ctx, operation.ID, state); err != nil {
+				return err
+			}
+			result.Operations = append(result.Operations, operation.ID)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// revertTask brings a task from the state from to the state to, where nil
+// stands for the trash. It fails with ErrUndoConflict when the task is no
+// longer in the state from.
+func (s *Services) revertTask(ctx context.Context, uid string, tid string, from *models.Task, to *models.Task) (task models.Task, err error) {
+	current, err := s.Tasks.getAnyTask(ctx, tid)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return task, err
+	}
+
+	var found *models.Task
+	if err == nil {
+		found = &current
+	}
+
+	if !models.TaskInState(found, from) {
+		return task, fmt.Errorf("%w: task %s was changed since", ErrUndoConflict, tid)
+	}
+
+	target := from
+	if to != nil {
+		target = to
+	}
+
+	if _, err := s.AuthorizeTasksList(ctx, target.ListID, uid, models.RoleEditor); err != nil {
+		if strings.HasPrefix(err.Error(), "permission denied") {
+			return task, err
+		}
+		return task, fmt.Errorf("%w: tasks list of task %s is not available", ErrUndoConflict, tid)
+	}
+
+	switch {
+	case to == nil:
+		if _, err := s.Tasks.DeleteTask(ctx, tid, current.UserID); err != nil {
+			return task, err
+		}
+	case from == nil:
+		if _, err := s.Tasks.RestoreTask(ctx, tid, current.UserID); err != nil {
+			return task, err
+		}
+		if err := s.Reminders.rearmTasks(ctx, []string{tid}, *current.DeletedAt); err != nil {
+			return task, fmt.Errorf("can not reschedule reminders: %s", err.Error())
+		}
+	default:
+		if err := s.Tasks.setTaskState(ctx, current, *to); err != nil {
+			return task, err
+		}
+	}
+
+	task, err = s.Tasks.getAnyTask(ctx, tid)
+	if err != nil {
+		return task, err
+	}
+
+	if err := s.invalidateStats(ctx, current.UserID, taskListIDs(current, task)...); err != nil {
+		return task, err
+	}
+
+	if to != nil {
+		if err := s.Reminders.RescheduleTask(ctx, task); err != nil {
+			return task, fmt.Errorf("can not reschedule reminders: %s", err.Error())
+		}
+	}
+
+	if found != nil && found.DeletedAt == nil && !found.Complete && task.DeletedAt == nil && task.Complete {
+		if err := s.notifyUnblocked(ctx, task); err != nil {
+			return task, err
+		}
+	}
+
+	before, after := current, task
+	if from == nil {
+		before = models.Task{}
+	}
+	if to == nil {
+		after = models.Task{}
+	}
+
+	if err := s.recordActivity(ctx, uid, []models.Task{before}, []models.Task{after}); err != nil {
+		return task, err
+	}
+
+	return task, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/utils/mongodb"
+	"main/utils/position"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// positioned is the part of an ordered document needed to move it.
+type positioned struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Position string             `bson:"position"`
+}
+
+var positionOrder = bson.D{{Key: "position", Value: 1}, {Key: "CreatedAt", Value: 1}}
+
+// nextPosition returns a key that places a new document after every
+// document matching scope. Every append lengthens the last key a little, so
+// once it grows too long the scope is rebalanced first.
+func nextPosition(ctx context.Context, collection *mongo.Collection, scope bson.M) (string, error) {
+	key, err := positionAfterLast(ctx, collection, scope)
+	if err != nil || len(key) <= position.MaxLength {
+		return key, err
+	}
+
+	err = mongodb.WithTransaction(ctx, collection.Database(), func(sc mongo.SessionContext) error {
+		if err := rebalancePositions(sc, collection, scope); err != nil {
+			return err
+		}
+
+		key, err = positionAfterLast(sc, collection, scope)
+
+		return err
+	})
+
+	return key, err
+}
+
+func positionAfterLast(ctx context.Context, collection *mongo.Collection, scope bson.M) (string, error) {
+	var last positioned
+
+	err := collection.FindOne(ctx, scope, options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+
+	return position.Between(last.Position, "")
+}
+
+// movePosition gives the document oid a key between its new neighbours in
+// scope. Only the moved document is written, unless the keys became too long
+// and the whole scope is rebalanced.
+func movePosition(ctx context.Context, collection *mongo.Collection, oid primitive.ObjectID, scope bson.M, after, before string) error {
+	// The neighbours are read in the transaction that writes the key, so the
+	// key is computed from one snapshot of the scope and a rebalance is never
+	// left half written.
+	return mongodb.WithTransaction(ctx, collection.Database(), func(sc mongo.SessionContext) error {
+		key, err := positionBetween(sc, collection, oid, scope, after, before)
+		if err != nil {
+			// Neighbours sharing a key, or keys with no room in between, are
+			// spread apart by rebalancing.
+			if err := rebalancePositions(sc, collection, scope); err != nil {
+				return err
+			}
+
+			key, err = positionBetween(sc, collection, oid, scope, after, before)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = collection.UpdateOne(sc, bson.M{"_id": oid}, bson.M{"$set": bson.M{"position": key}})
+		if err != nil {
+			return err
+		}
+
+		if len(key) > position.MaxLength {
+			return rebalancePositions(sc, collection, scope)
+		}
+
+		return nil
+	})
+}
+
+func positionBetween(ctx context.Context, collection *mongo.Collection, oid primitive.ObjectID, scope bson.M, after, before string) (string, error) {
+	others := bson.M{"_id": bson.M{"$ne": oid}}
+	for k, v := range scope {
+		others[k] = v
+	}
+
+	var lo, hi string
+
+	if after != "" {
+		neighbour, err := findPositioned(ctx, collection, after, others)
+		if err != nil {
+			return "", err
+		}
+		lo = neighbour.Position
+	}
+
+	if before != "" {
+		neighbour, err := findPositioned(ctx, collection, before, others)
+		if err != nil {
+			return "", err
+		}
+		hi = neighbour.Position
+	}
+
+	switch {
+	case after != "" && before == "":
+		next, err := findAdjacent(ctx, collection, others, bson.M{"$gt": lo}, 1)
+		if err != nil {
+			return "", err
+		}
+		hi = next
+	case after == "" && before != "":
+		prev, err := findAdjacent(ctx, collection, others, bson.M{"$lt": hi}, -1)
+		if err != nil {
+			return "", err
+		}
+		lo = prev
+	case after == "" && before == "":
+		last, err := findAdjacent(ctx, collection, others, bson.M{"$gt": ""}, -1)
+		if err != nil {
+			return "", err
+		}
+		lo = last
+	}
+
+	return position.Between(lo, hi)
+}
+
+func findPositioned(ctx context.Context, collection *mongo.Collection, id string, scope bson.M) (p positioned, err error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return p, err
+	}
+
+	filter := bson.M{"_id": oid}
+	for k, v := range scope {
+		if k != "_id" {
+			filter[k] = v
+		}
+	}
+
+	err = collection.FindOne(ctx, filter).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return p, fmt.Errorf("neighbour %s not found", id)
+	}
+
+	return p, err
+}
+
+// findAdjacent returns the position of the first document in scope matching
+// cond in the given direction, or an empty string when there is none.
+func findAdjacent(ctx context.Context, collection *mongo.Collection, scope bson.M, cond bson.M, direction int) (string, error) {
+	filter := bson.M{"position": cond}
+	for k, v := range scope {
+		filter[k] = v
+	}
+
+	var p positioned
+
+	err := collection.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "position", Value: direction}})).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+
+	return p.Position, err
+}
+
+// rebalancePositions rewrites the keys of every document in scope with
+// short, evenly spaced ones while keeping their order.
+func rebalancePositions(ctx context.Context, collection *mongo.Collection, scope bson.M) error {
+	result, err := collection.Find(ctx, scope, options.Find().SetSort(positionOrder).SetProjection(bson.M{"position": 1}))
+	if err != nil {
+		return err
+	}
+
+	var docs []positioned
+	if err := result.All(ctx, &docs); err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	keys := position.Spread(len(docs))
+	writes := make([]mongo.WriteModel, len(docs))
+	for i, doc := range docs {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": keys[i]}})
+	}
+
+	_, err = collection.BulkWrite(ctx, writes)
+
+	return err
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"main/utils/notifier"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// reminderLease is how long a claimed reminder stays locked. A reminder
+	// whose delivery did not finish in time is picked up again.
+	reminderLease       = time.Minute
+	reminderMaxAttempts = 10
+)
+
+type Reminders struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewRemindersService(db *mongo.Database, logger *logging.Logger) *Reminders {
+	remindersCollection := db.Collection("reminders")
+
+	return &Reminders{
+		collection: remindersCollection,
+		logger:     logger,
+	}
+}
+
+func (s Reminders) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "fired", Value: 1}, {Key: "fire_at", Value: 1}}},
+		{Keys: bson.D{{Key: "task_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+
+	return err
+}
+
+func (s Reminders) GetAllUserReminders(ctx context.Context, uid string, tid string) (reminders []models.Reminder, err error) {
+	filter := bson.M{"user_id": uid}
+	if tid != "" {
+		filter["task_id"] = tid
+	}
+
+	result, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "fire_at", Value: 1}}))
+	if err != nil {
+		return reminders, err
+	}
+
+	err = result.All(ctx, &reminders)
+
+	return reminders, err
+}
+
+func (s Reminders) AddReminder(ctx context.Context, reminder *models.CreateReminderDTO) (r models.Reminder, err error) {
+	result, err := s.collection.InsertOne(ctx, reminder)
+	if err != nil {
+		return r, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return r, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *reminder.Build(oid.Hex()), nil
+}
+
+func (s Reminders) DeleteReminder(ctx context.Context, rid string, uid string) (id string, err error) {
+	roid, err := primitive.ObjectIDFromHex(rid)
+	if err != nil {
+		return rid, err
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": roid, "user_id": uid})
+	if err != nil {
+		return rid, err
+	}
+
+	if result.DeletedCount == 0 {
+		return "", fmt.Errorf("reminder not found")
+	}
+
+	return rid, err
+}
+
+func (s Reminders) DeleteTasksReminders(ctx context.Context, tids []string) error {
+	if len(tids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": tids}})
+
+	return err
+}
+
+// RescheduleTask moves the relative reminders of a task after its due date
+// changed. Reminders that fired for the old date are armed again when they
+// are due in the future for the new one.
+func (s Reminders) RescheduleTask(ctx context.Context, task models.Task) error {
+	result, err := s.collection.Find(ctx, bson.M{
+		"task_id":        task.ID,
+		"before_minutes": bson.M{"$ne": nil},
+	})
+	if err != nil {
+		return err
+	}
+
+	var reminders []models.Reminder
+	if err := result.All(ctx, &reminders); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		roid, err := primitive.ObjectIDFromHex(reminder.ID)
+		if err != nil {
+			return err
+		}
+
+		fireAt := models.ReminderFireAt(reminder.BeforeMinutes, nil, task.DueInstant())
+
+		update := bson.M{"fire_at": fireAt}
+		if reminder.Fired {
+			if fireAt == nil || !fireAt.After(now) {
+				continue
+			}
+			update["fired"] = false
+			update["fired_at"] = nil
+			update["attempts"] = 0
+		}
+
+		_, err = s.collection.UpdateOne(ctx, bson.M{"_id": roid}, bson.M{"$set": update})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyRelativeReminders gives the next occurrence of a recurring task the
+// relative reminders of the task it follows, fired or not.
+func (s Reminders) copyRelativeReminders(ctx context.Context, task models.Task, next models.Task) error {
+	result, err := s.collection.Find(ctx, bson.M{
+		"task_id":        task.ID,
+		"before_minutes": bson.M{"$ne": nil},
+	})
+	if err != nil {
+		return err
+	}
+
+	var reminders []models.Reminder
+	if err := result.All(ctx, &reminders); err != nil {
+		return err
+	}
+
+	copied := map[int]bool{}
+	for _, reminder := range reminders {
+		if copied[*reminder.BeforeMinutes] {
+			continue
+		}
+		copied[*reminder.BeforeMinutes] = true
+
+		_, err := s.AddReminder(ctx, &models.CreateReminderDTO{
+			UserID:        reminder.UserID,
+			TaskID:        next.ID,
+			BeforeMinutes: reminder.BeforeMinutes,
+			FireAt:        models.ReminderFireAt(reminder.BeforeMinutes, nil, next.DueInstant()),
+			CreatedAt:     time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rearmTasks brings back the reminders of restored tasks that the scheduler
+// dropped while the tasks were in the trash, that is since deletedAt. Those
+// that are already due fire on the next run.
+func (s Reminders) rearmTasks(ctx context.Context, tids []string, deletedAt time.Time) error {
+	if len(tids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.UpdateMany(ctx,
+		bson.M{
+			"task_id":  bson.M{"$in": tids},
+			"fired":    true,
+			"fired_at": bson.M{"$gte": deletedAt},
+		},
+		bson.M{"$set": bson.M{"fired": false, "fired_at": nil, "attempts": 0}},
+	)
+
+	return err
+}
+
+// claimDue locks the next reminder that is due and not being delivered by
+// another run. It returns mongo.ErrNoDocuments when nothing is due.
+func (s Reminders) claimDue(ctx context.Context, now time.Time) (r models.Reminder, err error) {
+	result := s.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"fired":        false,
+			"fire_at":      bson.M{"$lte": now},
+			"locked_until": bson.M{"$lte": now},
+			"attempts":     bson.M{"$lt": reminderMaxAttempts},
+		},
+		bson.M{
+			"$set": bson.M{"locked_until": now.Add(reminderLease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "fire_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		return r, result.Err()
+	}
+
+	err = result.Decode(&r)
+
+	return r, err
+}
+
+func (s Reminders) markFired(ctx context.Context, rid string) error {
+	roid, err := primitive.ObjectIDFromHex(rid)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": roid}, bson.M{
+		"$set": bson.M{"fired": true, "fired_at": time.Now()},
+	})
+
+	return err
+}
+
+// FireDueReminders delivers every due reminder through n. A reminder is only
+// marked as fired after a successful delivery, so failures are retried on a
+// later run until reminderMaxAttempts is reached.
+func (s *Services) FireDueReminders(ctx context.Context, n notifier.Notifier) error {
+	for {
+		reminder, err := s.Reminders.claimDue(ctx, time.Now())
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		notification := notifier.Notification{
+			ReminderID: reminder.ID,
+			UserID:     reminder.UserID,
+			TaskID:     reminder.TaskID,
+			Kind:       reminder.Kind,
+			FireAt:     *reminder.FireAt,
+		}
+
+		// Reminders of trashed or completed tasks, and of tasks the user can
+		// no longer see, are dropped silently.
+		task, err := s.AuthorizeTask(ctx, reminder.TaskID, reminder.UserID, models.RoleViewer)
+		if err != nil || task.Complete {
+			if err := s.Reminders.markFired(ctx, reminder.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		notification.Title = task.Title
+		notification.DueAt = task.DueAt
+
+		if user, err := s.Users.FindUserByID(ctx, reminder.UserID); err == nil {
+			notification.Email = user.Email
+		}
+
+		if err := n.Notify(ctx, notification); err != nil {
+			s.Reminders.logger.Errorf("can not deliver reminder %s: %s", reminder.ID, err.Error())
+			continue
+		}
+
+		if err := s.Reminders.markFired(ctx, reminder.ID); err != nil {
+			return err
+		}
+	}
+}
@@ -1,25 +1,161 @@
 package services
 
 import (
+	"context"
 	"main/utils/logging"
+	"main/utils/mongodb"
 
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type Services struct {
-	Users      *Users
-	TasksLists *TasksLists
-	Tasks      *Tasks
+	Users        *Users
+	TasksLists   *TasksLists
+	Tasks        *Tasks
+	Reminders    *Reminders
+	Tags         *Tags
+	Folders      *Folders
+	Members      *Members
+	ShareLinks   *ShareLinks
+	Comments     *Comments
+	Attachments  *Attachments
+	Activity     *Activity
+	Operations   *Operations
+	Dependencies *Dependencies
+	TimeEntries  *TimeEntries
+	stats        *statsCache
+	db           *mongo.Database
+	migrations   *mongo.Collection
+	logger       *logging.Logger
 }
 
 func NewServices(db *mongo.Database, logger *logging.Logger) *Services {
 	usersService := NewUsersService(db, logger)
-	tasksListsService := NewTasksListsService(db, logger)
+	stats := &statsCache{}
+	tasksListsService := NewTasksListsService(db, stats, logger)
 	tasksService := NewTasksService(db, logger)
+	remindersService := NewRemindersService(db, logger)
+	tagsService := NewTagsService(db, logger)
+	foldersService := NewFoldersService(db, logger)
+	membersService := NewMembersService(db, logger)
+	shareLinksService := NewShareLinksService(db, logger)
+	commentsService := NewCommentsService(db, logger)
+	attachmentsService := NewAttachmentsService(db, logger)
+	activityService := NewActivityService(db, logger)
+	operationsService := NewOperationsService(db, logger)
+	dependenciesService := NewDependenciesService(db, logger)
+	timeEntriesService := NewTimeEntriesService(db, logger)
 
 	return &Services{
-		Users:      usersService,
-		TasksLists: tasksListsService,
-		Tasks:      tasksService,
+		Users:        usersService,
+		TasksLists:   tasksListsService,
+		Tasks:        tasksService,
+		Reminders:    remindersService,
+		Tags:         tagsService,
+		Folders:      foldersService,
+		Members:      membersService,
+		ShareLinks:   shareLinksService,
+		Comments:     commentsService,
+		Attachments:  attachmentsService,
+		Activity:     activityService,
+		Operations:   operationsService,
+		Dependencies: dependenciesService,
+		TimeEntries:  timeEntriesService,
+		stats:        stats,
+		db:           db,
+		migrations:   db.Collection("migrations"),
+		logger:       logger,
 	}
 }
+
+type commitHooksKey struct{}
+
+// Transaction runs fn in a transaction that every service call made with
+// the context passed to fn takes part in. Functions passed to afterCommit
+// inside fn run once the outermost transaction has committed.
+func (s *Services) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(commitHooksKey{}).(*[]func()); ok {
+		return mongodb.WithTransaction(ctx, s.db, func(sc mongo.SessionContext) error {
+			return fn(sc)
+		})
+	}
+
+	hooks := &[]func(){}
+	err := mongodb.WithTransaction(context.WithValue(ctx, commitHooksKey{}, hooks), s.db, func(sc mongo.SessionContext) error {
+		// A retried transaction starts over, so do its hooks.
+		*hooks = (*hooks)[:0]
+		return fn(sc)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range *hooks {
+		hook()
+	}
+
+	return nil
+}
+
+// afterCommit runs fn once the transaction of ctx has committed, or right
+// away outside of a transaction.
+func (s *Services) afterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+
+	fn()
+}
+
+func (s *Services) CreateIndexes(ctx context.Context) error {
+	if err := s.TasksLists.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Tasks.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Reminders.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Tags.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Folders.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Members.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.ShareLinks.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Comments.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Attachments.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Activity.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Operations.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.Dependencies.CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	return s.TimeEntries.CreateIndexes(ctx)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSharePassword is returned for a protected share link opened with a
+// wrong or missing password.
+var ErrSharePassword = fmt.Errorf("wrong share link password")
+
+type ShareLinks struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewShareLinksService(db *mongo.Database, logger *logging.Logger) *ShareLinks {
+	shareLinksCollection := db.Collection("share-links")
+
+	return &ShareLinks{
+		collection: shareLinksCollection,
+		logger:     logger,
+	}
+}
+
+// CreateIndexes also lets MongoDB remove links once they expire.
+func (s ShareLinks) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "list_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	return err
+}
+
+func (s ShareLinks) GetListShareLinks(ctx context.Context, tlid string) (links []models.ShareLink, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"list_id": tlid}, options.Find().SetSort(bson.D{{Key: "CreatedAt", Value: 1}}))
+	if err != nil {
+		return links, err
+	}
+
+	err = result.All(ctx, &links)
+
+	return links, err
+}
+
+func (s ShareLinks) AddShareLink(ctx context.Context, link *models.CreateShareLinkDTO, token string) (l models.ShareLink, err error) {
+	result, err := s.collection.InsertOne(ctx, link)
+	if err != nil {
+		return l, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return l, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *link.Build(oid.Hex(), token), nil
+}
+
+// RevokeShareLink deletes the link, so its token stops working at once.
+func (s ShareLinks) RevokeShareLink(ctx context.Context, lkid string, tlid string) (id string, err error) {
+	lkoid, err := primitive.ObjectIDFromHex(lkid)
+	if err != nil {
+		return lkid, err
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": lkoid, "list_id": tlid})
+	if err != nil {
+		return lkid, err
+	}
+
+	if result.DeletedCount == 0 {
+		return "", fmt.Errorf("share link not found")
+	}
+
+	return lkid, nil
+}
+
+// getShareLink finds an unexpired link by its token.
+func (s ShareLinks) getShareLink(ctx context.Context, token string) (link models.ShareLink, err error) {
+	err = s.collection.FindOne(ctx, bson.M{
+		"token_hash": models.HashShareToken(token),
+		"$or": bson.A{
+			bson.M{"expires_at": nil},
+			bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+		},
+	}).Decode(&link)
+
+	return link, err
+}
+
+func (s ShareLinks) deleteListsShareLinks(ctx context.Context, tlids []string) error {
+	if len(tlids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"list_id": bson.M{"$in": tlids}})
+
+	return err
+}
+
+// CreateShareLink creates a link to a list the user owns.
+func (s *Services) CreateShareLink(ctx context.Context, tlid string, uid string, rb *models.CreateShareLinkRB) (l models.ShareLink, err error) {
+	tasksList, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner)
+	if err != nil {
+		return l, err
+	}
+
+	if rb.ExpiresAt != nil && !rb.ExpiresAt.After(time.Now()) {
+		return l, fmt.Errorf("expires_at must be in the future")
+	}
+
+	link, token, err := rb.Build(tasksList, uid)
+	if err != nil {
+		return l, err
+	}
+
+	return s.ShareLinks.AddShareLink(ctx, link, token)
+}
+
+func (s *Services) GetShareLinks(ctx context.Context, tlid string, uid string) (links []models.ShareLink, err error) {
+	if _, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner); err != nil {
+		return links, err
+	}
+
+	return s.ShareLinks.GetListShareLinks(ctx, tlid)
+}
+
+func (s *Services) RevokeShareLink(ctx context.Context, tlid string, lkid string, uid string) (id string, err error) {
+	if _, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner); err != nil {
+		return "", err
+	}
+
+	return s.ShareLinks.RevokeShareLink(ctx, lkid, tlid)
+}
+
+// GetSharedTasksList returns the public view of the list behind token. It
+// fails with mongo.ErrNoDocuments for unknown, expired or revoked links and
+// lists in the trash, and with ErrSharePassword for a wrong password.
+func (s *Services) GetSharedTasksList(ctx context.Context, token string, password string) (shared models.SharedTasksList, err error) {
+	link, err := s.ShareLinks.getShareLink(ctx, token)
+	if err != nil {
+		return shared, err
+	}
+
+	if !link.CheckPassword(password) {
+		return shared, ErrSharePassword
+	}
+
+	tasksList, err := s.TasksLists.getTasksList(ctx, link.ListID)
+	if err != nil {
+		return shared, err
+	}
+
+	tasks, err := s.Tasks.getListTasks(ctx, link.ListID, link.OwnerID)
+	if err != nil {
+		return shared, err
+	}
+
+	return models.NewSharedTasksList(tasksList, tasks), nil
+}
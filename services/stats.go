@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"main/models"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// statsCache keeps the tasks list statistics of a user in a Redis hash with
+// one field per day and set of shared lists they were computed for. Every
+// task write drops the hashes of the owner and the members of the lists it
+// touched once it is committed. Without a Redis client the cache is
+// disabled.
+type statsCache struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+func (c *statsCache) key(uid string) string {
+	return fmt.Sprintf("tasks-lists:stats:%s", uid)
+}
+
+// field names the statistics of a day for a set of shared lists, which
+// changes with the lists a request includes.
+func (c *statsCache) field(today time.Time, shared []string) string {
+	lids := append([]string(nil), shared...)
+	sort.Strings(lids)
+	sum := sha256.Sum256([]byte(strings.Join(lids, ",")))
+
+	return fmt.Sprintf("%d:%s", today.Unix(), hex.EncodeToString(sum[:8]))
+}
+
+func (c *statsCache) get(ctx context.Context, uid string, today time.Time, shared []string) (map[string]models.TasksListStats, bool) {
+	if c.redis == nil {
+		return nil, false
+	}
+
+	value, err := c.redis.HGet(ctx, c.key(uid), c.field(today, shared)).Result()
+	if err != nil {
+		return nil, false
+	}
+
+	var stats map[string]models.TasksListStats
+	if err := json.Unmarshal([]byte(value), &stats); err != nil {
+		return nil, false
+	}
+
+	return stats, true
+}
+
+func (c *statsCache) set(ctx context.Context, uid string, today time.Time, shared []string, stats map[string]models.TasksListStats) {
+	if c.redis == nil {
+		return
+	}
+
+	value, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+
+	pipe := c.redis.TxPipeline()
+	pipe.HSet(ctx, c.key(uid), c.field(today, shared), value)
+	pipe.Expire(ctx, c.key(uid), c.ttl)
+	pipe.Exec(ctx)
+}
+
+func (c *statsCache) invalidate(ctx context.Context, uid string) {
+	if c.redis == nil {
+		return
+	}
+
+	c.redis.Del(ctx, c.key(uid))
+}
+
+// invalidateStats drops the cached statistics of the owner and of the
+// accepted members of the lists once the transaction of ctx has committed,
+// so a concurrent read can not cache the counts from before the write.
+func (s *Services) invalidateStats(ctx context.Context, owner string, lids ...string) error {
+	if s.stats.redis == nil {
+		return nil
+	}
+
+	uids, err := s.Members.getListsMemberIDs(ctx, lids)
+	if err != nil {
+		return err
+	}
+
+	s.afterCommit(ctx, func() {
+		s.stats.invalidate(context.Background(), owner)
+		for _, uid := range uids {
+			s.stats.invalidate(context.Background(), uid)
+		}
+	})
+
+	return nil
+}
+
+// taskListIDs returns the lists of the tasks, each once.
+func taskListIDs(tasks ...models.Task) []string {
+	seen := map[string]bool{}
+	lids := []string{}
+	for _, task := range tasks {
+		if task.ListID != "" && !seen[task.ListID] {
+			seen[task.ListID] = true
+			lids = append(lids, task.ListID)
+		}
+	}
+
+	return lids
+}
+
+// UseStatsCache caches tasks list statistics in Redis for ttl.
+func (s *Services) UseStatsCache(rdb *redis.Client, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	s.stats.redis = rdb
+	s.stats.ttl = ttl
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Tags struct {
+	collection *mongo.Collection
+	logger     *logging.Logger
+}
+
+func NewTagsService(db *mongo.Database, logger *logging.Logger) *Tags {
+	tagsCollection := db.Collection("tags")
+
+	return &Tags{
+		collection: tagsCollection,
+		logger:     logger,
+	}
+}
+
+func (s Tags) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+func (s Tags) GetAllUserTags(ctx context.Context, uid string) (tags []models.Tag, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return tags, err
+	}
+
+	err = result.All(ctx, &tags)
+
+	return tags, err
+}
+
+// CheckUserTags returns an error unless every id is a tag of the user.
+func (s Tags) CheckUserTags(ctx context.Context, tgids []string, uid string) error {
+	if len(tgids) == 0 {
+		return nil
+	}
+
+	unique := map[primitive.ObjectID]bool{}
+	for _, tgid := range tgids {
+		tgoid, err := primitive.ObjectIDFromHex(tgid)
+		if err != nil {
+			return fmt.Errorf("invalid tag id %q", tgid)
+		}
+		unique[tgoid] = true
+	}
+
+	oids := make([]primitive.ObjectID, 0, len(unique))
+	for oid := range unique {
+		oids = append(oids, oid)
+	}
+
+	count, err := s.collection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": oids}, "user_id": uid})
+	if err != nil {
+		return err
+	}
+
+	if int(count) != len(oids) {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// getTagNames maps the tags of tgids to their names, whoever owns them.
+func (s Tags) getTagNames(ctx context.Context, tgids []string) (names map[string]string, err error) {
+	names = map[string]string{}
+
+	oids := make([]primitive.ObjectID, 0, len(tgids))
+	for _, tgid := range tgids {
+		if oid, err := primitive.ObjectIDFromHex(tgid); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+
+	if len(oids) == 0 {
+		return names, nil
+	}
+
+	result, err := s.collection.Find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+	if err != nil {
+		return names, err
+	}
+
+	var tags []models.Tag
+	if err := result.All(ctx, &tags); err != nil {
+		return names, err
+	}
+
+	for _, tag := range tags {
+		names[tag.ID] = tag.Name
+	}
+
+	return names, nil
+}
+
+func (s Tags) AddTag(ctx context.Context, tag *models.CreateTagDTO) (t models.Tag, err error) {
+	result, err := s.collection.InsertOne(ctx, tag)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return t, fmt.Errorf("tag %q already exist", tag.Name)
+		}
+		return t, err
+	}
+
+	oid, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return t, fmt.Errorf("failed convert objectid to hex")
+	}
+
+	return *tag.Build(oid.Hex()), nil
+}
+
+// UpdateTag renames or recolors a tag. Tasks reference tags by id, so they
+// pick up the change without being rewritten.
+func (s Tags) UpdateTag(ctx context.Context, tgid string, uid string, tag *models.UpdateTagDTO) (t *models.Tag, err error) {
+	tgoid, err := primitive.ObjectIDFromHex(tgid)
+	if err != nil {
+		return t, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": tgoid, "user_id": uid}, bson.M{"$set": tag},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		if mongo.IsDuplicateKeyError(result.Err()) {
+			return t, fmt.Errorf("tag %q already exist", tag.Name)
+		}
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
+
+// DeleteTag removes the tag and detaches it from every task of the user in
+// one transaction.
+func (s *Services) DeleteTag(ctx context.Context, tgid string, uid string) (id string, err error) {
+	tgoid, err := primitive.ObjectIDFromHex(tgid)
+	if err != nil {
+		return tgid, err
+	}
+
+	err = s.Transaction(ctx, func(sc context.Context) error {
+		result, err := s.Tags.collection.DeleteOne(sc, bson.M{"_id": tgoid, "user_id": uid})
+		if err != nil {
+			return err
+		}
+
+		if result.DeletedCount == 0 {
+			return fmt.Errorf("tag not found")
+		}
+
+		return s.Tasks.RemoveTag(sc, tgid, uid)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return tgid, nil
+}
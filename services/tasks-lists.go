@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"main/models"
 	"main/utils/logging"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,21 +15,48 @@ import (
 
 type TasksLists struct {
 	collection *mongo.Collection
+	tasks      *mongo.Collection
+	stats      *statsCache
 	logger     *logging.Logger
 }
 
-func NewTasksListsService(db *mongo.Database, logger *logging.Logger) *TasksLists {
+func NewTasksListsService(db *mongo.Database, stats *statsCache, logger *logging.Logger) *TasksLists {
 	tasksCollection := db.Collection("tasks-lists")
 
 	return &TasksLists{
 		collection: tasksCollection,
+		tasks:      db.Collection("tasks"),
+		stats:      stats,
 		logger:     logger,
 	}
 }
 
-func (s TasksLists) GetAllUserTasksLists(ctx context.Context, uid string) (tasksLists []models.TasksList, err error) {
-	result, err := s.collection.Find(ctx, bson.M{"user_id": uid})
-	if result.Err() != nil {
+func (s TasksLists) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "position", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"inbox": true}),
+		},
+	})
+
+	return err
+}
+
+// GetAllUserTasksLists returns the user's lists together with the shared
+// lists.
+func (s TasksLists) GetAllUserTasksLists(ctx context.Context, uid string, shared []string, include models.ListsInclude) (tasksLists []models.TasksList, err error) {
+	query := bson.M{"$or": ownOrShared(uid, shared), "deleted_at": nil}
+	if !include.Archived {
+		query["archived_at"] = nil
+	}
+	if !include.Hidden {
+		query["hidden"] = bson.M{"$ne": true}
+	}
+
+	result, err := s.collection.Find(ctx, query, options.Find().SetSort(positionOrder))
+	if err != nil {
 		return tasksLists, err
 	}
 
@@ -37,13 +65,95 @@ func (s TasksLists) GetAllUserTasksLists(ctx context.Context, uid string) (tasks
 	return tasksLists, err
 }
 
+// ownOrShared matches the lists of the user and the shared lists.
+func ownOrShared(uid string, shared []string) bson.A {
+	oids := make([]primitive.ObjectID, 0, len(shared))
+	for _, tlid := range shared {
+		if oid, err := primitive.ObjectIDFromHex(tlid); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+
+	return bson.A{bson.M{"user_id": uid}, bson.M{"_id": bson.M{"$in": oids}}}
+}
+
+func (s TasksLists) getTasksList(ctx context.Context, tlid string) (tasksList models.TasksList, err error) {
+	tloid, err := primitive.ObjectIDFromHex(tlid)
+	if err != nil {
+		return tasksList, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": tloid, "deleted_at": nil}).Decode(&tasksList)
+
+	return tasksList, err
+}
+
+// getTasksListsByIDs maps the lists of tlids to the lists, whoever owns
+// them and whether or not they are in the trash.
+func (s TasksLists) getTasksListsByIDs(ctx context.Context, tlids []string) (tasksLists map[string]models.TasksList, err error) {
+	tasksLists = map[string]models.TasksList{}
+
+	oids := make([]primitive.ObjectID, 0, len(tlids))
+	for _, tlid := range tlids {
+		if oid, err := primitive.ObjectIDFromHex(tlid); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+
+	if len(oids) == 0 {
+		return tasksLists, nil
+	}
+
+	result, err := s.collection.Find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+	if err != nil {
+		return tasksLists, err
+	}
+
+	var found []models.TasksList
+	if err := result.All(ctx, &found); err != nil {
+		return tasksLists, err
+	}
+
+	for _, tasksList := range found {
+		tasksLists[tasksList.ID] = tasksList
+	}
+
+	return tasksLists, nil
+}
+
+// getListNames maps the lists of tlids to their names, whoever owns them.
+func (s TasksLists) getListNames(ctx context.Context, tlids []string) (names map[string]string, err error) {
+	tasksLists, err := s.getTasksListsByIDs(ctx, tlids)
+	if err != nil {
+		return names, err
+	}
+
+	names = make(map[string]string, len(tasksLists))
+	for tlid, tasksList := range tasksLists {
+		names[tlid] = tasksList.Name
+	}
+
+	return names, nil
+}
+
 func (s TasksLists) GetUserTasksList(ctx context.Context, tlid string, uid string) (tasksList models.TasksList, err error) {
 	tloid, err := primitive.ObjectIDFromHex(tlid)
 	if err != nil {
 		return tasksList, err
 	}
 
-	result := s.collection.FindOne(ctx, bson.M{"_id": tloid, "user_id": uid})
+	result := s.collection.FindOne(ctx, bson.M{"_id": tloid, "user_id": uid, "deleted_at": nil})
+	if result.Err() != nil {
+		return tasksList, result.Err()
+	}
+
+	err = result.Decode(&tasksList)
+
+	return tasksList, err
+}
+
+func (s TasksLists) GetUserInbox(ctx context.Context, uid string) (tasksList models.TasksList, err error) {
+	result := s.collection.FindOne(ctx, bson.M{"user_id": uid, "inbox": true})
 	if result.Err() != nil {
 		return tasksList, result.Err()
 	}
@@ -53,7 +163,22 @@ func (s TasksLists) GetUserTasksList(ctx context.Context, tlid string, uid strin
 	return tasksList, err
 }
 
+// EnsureInbox returns the user's Inbox and creates it when it is missing.
+func (s TasksLists) EnsureInbox(ctx context.Context, uid string) (models.TasksList, error) {
+	inbox, err := s.GetUserInbox(ctx, uid)
+	if err != mongo.ErrNoDocuments {
+		return inbox, err
+	}
+
+	return s.AddTasksList(ctx, models.NewInbox(uid))
+}
+
 func (s TasksLists) AddTasksList(ctx context.Context, task *models.CreateTasksListDTO) (u models.TasksList, err error) {
+	task.Position, err = nextPosition(ctx, s.collection, bson.M{"user_id": task.UserID})
+	if err != nil {
+		return u, err
+	}
+
 	result, err := s.collection.InsertOne(ctx, task)
 	if err != nil {
 		return u, err
@@ -74,7 +199,27 @@ func (s TasksLists) UpdateTasksList(ctx context.Context, tlid string, uid string
 	}
 
 	result := s.collection.FindOneAndUpdate(
-		ctx, bson.M{"_id": tloid, "user_id": uid}, bson.M{"$set": taskList},
+		ctx, bson.M{"_id": tloid, "user_id": uid, "deleted_at": nil}, bson.M{"$set": taskList},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
+
+func (s TasksLists) setStatuses(ctx context.Context, tlid string, uid string, statuses []models.WorkflowStatus) (t *models.TasksList, err error) {
+	tloid, err := primitive.ObjectIDFromHex(tlid)
+	if err != nil {
+		return t, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": tloid, "user_id": uid, "deleted_at": nil},
+		bson.M{"$set": bson.M{"statuses": statuses, "UpdatedAt": time.Now()}},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	)
 	if result.Err() != nil {
@@ -86,20 +231,516 @@ func (s TasksLists) UpdateTasksList(ctx context.Context, tlid string, uid string
 	return t, err
 }
 
-func (s TasksLists) DeleteTasksList(ctx context.Context, tlid string, uid string) (id string, err error) {
+// DeleteTasksList moves the tasks list to the trash.
+func (s TasksLists) DeleteTasksList(ctx context.Context, tlid string, uid string, deletedAt time.Time) (id string, err error) {
 	tloid, err := primitive.ObjectIDFromHex(tlid)
 	if err != nil {
 		return tlid, err
 	}
 
-	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": tloid, "user_id": uid})
+	result, err := s.collection.UpdateOne(
+		ctx, bson.M{"_id": tloid, "user_id": uid, "inbox": bson.M{"$ne": true}, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": deletedAt}},
+	)
 	if err != nil {
 		return tlid, err
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return "", fmt.Errorf("tasks list not found")
 	}
 
 	return tlid, err
 }
+
+func (s TasksLists) GetUserTrashedTasksLists(ctx context.Context, uid string) (tasksLists []models.TasksList, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid, "deleted_at": bson.M{"$ne": nil}},
+		options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}}))
+	if err != nil {
+		return tasksLists, err
+	}
+
+	err = result.All(ctx, &tasksLists)
+
+	return tasksLists, err
+}
+
+// RestoreTasksList takes the tasks list out of the trash. The returned list
+// still carries the time it was deleted at.
+func (s TasksLists) RestoreTasksList(ctx context.Context, tlid string, uid string) (t *models.TasksList, err error) {
+	tloid, err := primitive.ObjectIDFromHex(tlid)
+	if err != nil {
+		return t, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": tloid, "user_id": uid, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$set": bson.M{"deleted_at": nil}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	)
+	if result.Err() != nil {
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
+
+// getArchivedListIDs returns the archived lists among the user's lists and
+// the shared lists.
+func (s TasksLists) getArchivedListIDs(ctx context.Context, uid string, shared []string) ([]string, error) {
+	result, err := s.collection.Find(
+		ctx, bson.M{"$or": ownOrShared(uid, shared), "deleted_at": nil, "archived_at": bson.M{"$ne": nil}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasksLists []models.TasksList
+	if err := result.All(ctx, &tasksLists); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(tasksLists))
+	for i, tasksList := range tasksLists {
+		ids[i] = tasksList.ID
+	}
+
+	return ids, nil
+}
+
+// ArchiveTasksList archives the list. The Inbox can not be archived.
+func (s TasksLists) ArchiveTasksList(ctx context.Context, tlid string, uid string) (t *models.TasksList, err error) {
+	tloid, err := primitive.ObjectIDFromHex(tlid)
+	if err != nil {
+		return t, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": tloid, "user_id": uid, "inbox": bson.M{"$ne": true}, "deleted_at": nil, "archived_at": nil},
+		bson.M{"$set": bson.M{"archived_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() == mongo.ErrNoDocuments {
+		return t, fmt.Errorf("no active tasks list to archive")
+	}
+	if result.Err() != nil {
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
+
+func (s TasksLists) UnarchiveTasksList(ctx context.Context, tlid string, uid string) (t *models.TasksList, err error) {
+	tloid, err := primitive.ObjectIDFromHex(tlid)
+	if err != nil {
+		return t, err
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": tloid, "user_id": uid, "deleted_at": nil, "archived_at": bson.M{"$ne": nil}},
+		bson.M{"$set": bson.M{"archived_at": nil}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() == mongo.ErrNoDocuments {
+		return t, fmt.Errorf("no archived tasks list to unarchive")
+	}
+	if result.Err() != nil {
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
+
+// GetUserTasksListsStats counts the tasks of the user's own lists and of
+// the shared lists. Overdue and due today follow the views: timed tasks are
+// overdue after now, all-day ones once today has begun. Only the counts
+// that stay the same all day are cached, overdue timed tasks are counted
+// on every call.
+func (s TasksLists) GetUserTasksListsStats(ctx context.Context, uid string, shared []string, now, today time.Time) (map[string]models.TasksListStats, error) {
+	stats, ok := s.stats.get(ctx, uid, today, shared)
+	if !ok {
+		var err error
+		stats, err = s.countDailyStats(ctx, uid, shared, today)
+		if err != nil {
+			return nil, err
+		}
+
+		s.stats.set(ctx, uid, today, shared, stats)
+	}
+
+	result, err := s.tasks.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$or":         bson.A{bson.M{"user_id": uid}, bson.M{"list_id": bson.M{"$in": shared}}},
+			"deleted_at":  nil,
+			"complete":    false,
+			"due_all_day": bson.M{"$ne": true},
+			"due_at":      bson.M{"$lt": now},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$list_id", "overdue": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var overdue []struct {
+		ListID  string `bson:"_id"`
+		Overdue int    `bson:"overdue"`
+	}
+	if err := result.All(ctx, &overdue); err != nil {
+		return nil, err
+	}
+
+	for _, group := range overdue {
+		listStats := stats[group.ListID]
+		listStats.Overdue += group.Overdue
+		stats[group.ListID] = listStats
+	}
+
+	return stats, nil
+}
+
+// countDailyStats counts the tasks of every list in one aggregation, with
+// overdue counting only all-day tasks.
+func (s TasksLists) countDailyStats(ctx context.Context, uid string, shared []string, today time.Time) (map[string]models.TasksListStats, error) {
+	date := models.DateOf(today)
+	allDay := bson.M{"$eq": bson.A{"$due_all_day", true}}
+	incomplete := bson.M{"$eq": bson.A{"$complete", false}}
+	hasDue := bson.M{"$eq": bson.A{bson.M{"$type": "$due_at"}, "date"}}
+	count := func(cond bson.M) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+	}
+
+	result, err := s.tasks.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{bson.M{"user_id": uid}, bson.M{"list_id": bson.M{"$in": shared}}}, "deleted_at": nil}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$list_id",
+			"total":     bson.M{"$sum": 1},
+			"completed": count(bson.M{"$eq": bson.A{"$complete", true}}),
+			"overdue":   count(bson.M{"$and": bson.A{incomplete, hasDue, allDay, bson.M{"$lt": bson.A{"$due_at", date}}}}),
+			"due_today": count(bson.M{"$and": bson.A{incomplete, hasDue, bson.M{"$cond": bson.A{
+				allDay,
+				bson.M{"$and": bson.A{bson.M{"$gte": bson.A{"$due_at", date}}, bson.M{"$lt": bson.A{"$due_at", date.AddDate(0, 0, 1)}}}},
+				bson.M{"$and": bson.A{bson.M{"$gte": bson.A{"$due_at", today}}, bson.M{"$lt": bson.A{"$due_at", today.AddDate(0, 0, 1)}}}},
+			}}}}),
+			"last_activity": bson.M{"$max": "$UpdatedAt"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		ListID                string `bson:"_id"`
+		models.TasksListStats `bson:",inline"`
+	}
+	if err := result.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]models.TasksListStats, len(groups))
+	for _, group := range groups {
+		stats[group.ListID] = group.TasksListStats
+	}
+
+	return stats, nil
+}
+
+// AttachStats sets the statistics of every list. A list that was edited
+// after its last task reports its own update as the last activity.
+func (s TasksLists) AttachStats(ctx context.Context, uid string, tasksLists []models.TasksList, now, today time.Time) error {
+	shared := []string{}
+	for _, tasksList := range tasksLists {
+		if tasksList.UserID != uid {
+			shared = append(shared, tasksList.ID)
+		}
+	}
+
+	stats, err := s.GetUserTasksListsStats(ctx, uid, shared, now, today)
+	if err != nil {
+		return err
+	}
+
+	for i := range tasksLists {
+		listStats := stats[tasksLists[i].ID]
+		if listStats.LastActivity == nil || tasksLists[i].UpdatedAt.After(*listStats.LastActivity) {
+			updatedAt := tasksLists[i].UpdatedAt
+			listStats.LastActivity = &updatedAt
+		}
+		tasksLists[i].Stats = &listStats
+	}
+
+	return nil
+}
+
+// clearFolder moves the lists of a folder to the root level, trashed ones
+// included.
+func (s TasksLists) clearFolder(ctx context.Context, fid string, uid string) error {
+	_, err := s.collection.UpdateMany(ctx, bson.M{"user_id": uid, "folder_id": fid}, bson.M{"$set": bson.M{"folder_id": ""}})
+
+	return err
+}
+
+// purge permanently removes trashed tasks lists matching filter and returns
+// their ids.
+func (s TasksLists) purge(ctx context.Context, filter bson.M) (tlids []string, err error) {
+	if _, ok := filter["deleted_at"]; !ok {
+		filter["deleted_at"] = bson.M{"$ne": nil}
+	}
+
+	result, err := s.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return tlids, err
+	}
+
+	var docs []positioned
+	if err := result.All(ctx, &docs); err != nil {
+		return tlids, err
+	}
+
+	if len(docs) == 0 {
+		return tlids, nil
+	}
+
+	oids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		oids[i] = doc.ID
+		tlids = append(tlids, doc.ID.Hex())
+	}
+
+	_, err = s.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": oids}})
+
+	return tlids, err
+}
+
+// MoveTasksList places the list between two neighbours among the user's
+// lists.
+func (s TasksLists) MoveTasksList(ctx context.Context, tlid string, uid string, move *models.MoveRB) (t *models.TasksList, err error) {
+	if _, err := s.GetUserTasksList(ctx, tlid, uid); err != nil {
+		return t, err
+	}
+
+	tloid, _ := primitive.ObjectIDFromHex(tlid)
+
+	err = movePosition(ctx, s.collection, tloid, bson.M{"user_id": uid}, move.After, move.Before)
+	if err != nil {
+		return t, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": tloid}).Decode(&t)
+
+	return t, err
+}
+
+func (s TasksLists) backfillPositions(ctx context.Context) error {
+	userIDs, err := s.collection.Distinct(ctx, "user_id", bson.M{})
+	if err != nil {
+		return err
+	}
+
+	for _, uid := range userIDs {
+		if err := rebalancePositions(ctx, s.collection, bson.M{"user_id": uid}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteTasksList trashes the tasks list and handles its tasks according to
+// mode, all in one transaction.
+func (s *Services) DeleteTasksList(ctx context.Context, tlid string, uid string, mode string, target string) (id string, err error) {
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		tasksList, err := s.TasksLists.GetUserTasksList(ctx, tlid, uid)
+		if err != nil {
+			return fmt.Errorf("tasks list not found")
+		}
+
+		if tasksList.Inbox {
+			return fmt.Errorf("inbox can not be deleted")
+		}
+
+		deletedAt := time.Now()
+
+		if err := s.invalidateStats(ctx, uid, tlid, target); err != nil {
+			return err
+		}
+
+		switch mode {
+		case "cascade":
+			tasks, err := s.Tasks.getListTasks(ctx, tlid, uid)
+			if err != nil {
+				return err
+			}
+
+			if err := s.Tasks.trashListTasks(ctx, tlid, uid, deletedAt); err != nil {
+				return err
+			}
+
+			if err := s.recordOperation(ctx, uid, tasks, make([]models.Task, len(tasks))); err != nil {
+				return err
+			}
+		case "move":
+			if target == "" || target == tlid {
+				return fmt.Errorf("target_list_id must be another tasks list")
+			}
+
+			if _, err := s.TasksLists.GetUserTasksList(ctx, target, uid); err != nil {
+				return fmt.Errorf("can not find target tasks list: %s", err.Error())
+			}
+
+			tasks, err := s.Tasks.getListTasks(ctx, tlid, uid)
+			if err != nil {
+				return err
+			}
+
+			before := append([]models.Task(nil), tasks...)
+
+			moved, err := s.Tasks.moveToList(ctx, tasks, uid, target)
+			if err != nil {
+				return err
+			}
+
+			if err := s.recordOperation(ctx, uid, before, moved); err != nil {
+				return err
+			}
+		default:
+			count, err := s.Tasks.countListTasks(ctx, tlid, uid)
+			if err != nil {
+				return err
+			}
+
+			if count > 0 {
+				return fmt.Errorf("tasks list is not empty")
+			}
+		}
+
+		id, err = s.TasksLists.DeleteTasksList(ctx, tlid, uid, deletedAt)
+
+		return err
+	})
+
+	return id, err
+}
+
+// GetArchivedCounts counts the user's archived lists and the tasks in them.
+func (s *Services) GetArchivedCounts(ctx context.Context, uid string) (counts models.ArchivedCounts, err error) {
+	lids, err := s.TasksLists.getArchivedListIDs(ctx, uid, nil)
+	if err != nil {
+		return counts, err
+	}
+
+	counts.TasksLists = int64(len(lids))
+	if len(lids) == 0 {
+		return counts, nil
+	}
+
+	counts.Tasks, err = s.Tasks.collection.CountDocuments(ctx, bson.M{"user_id": uid, "list_id": bson.M{"$in": lids}, "deleted_at": nil})
+
+	return counts, err
+}
+
+// CreateTasksList adds a tasks list after checking that its folder belongs
+// to the user.
+func (s *Services) CreateTasksList(ctx context.Context, tasksList *models.CreateTasksListDTO) (t models.TasksList, err error) {
+	if tasksList.FolderID != "" {
+		if _, err := s.Folders.GetUserFolder(ctx, tasksList.FolderID, tasksList.UserID); err != nil {
+			return t, fmt.Errorf("can not find folder: %s", err.Error())
+		}
+	}
+
+	return s.TasksLists.AddTasksList(ctx, tasksList)
+}
+
+// UpdateTasksList updates a tasks list the user owns after checking that
+// its folder belongs to the user who created the list.
+func (s *Services) UpdateTasksList(ctx context.Context, tlid string, uid string, tasksList *models.UpdateTasksListDTO) (t *models.TasksList, err error) {
+	current, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner)
+	if err != nil {
+		return t, err
+	}
+
+	if tasksList.FolderID != "" {
+		if _, err := s.Folders.GetUserFolder(ctx, tasksList.FolderID, current.UserID); err != nil {
+			return t, fmt.Errorf("can not find folder: %s", err.Error())
+		}
+	}
+
+	return s.TasksLists.UpdateTasksList(ctx, tlid, current.UserID, tasksList)
+}
+
+// UpdateTasksListStatuses replaces the workflow of a list the user owns.
+// Tasks in removed statuses, or in statuses that moved between done and
+// not done, fall back to the status matching their completion.
+func (s *Services) UpdateTasksListStatuses(ctx context.Context, tlid string, uid string, statuses []models.WorkflowStatus) (t *models.TasksList, err error) {
+	current, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleOwner)
+	if err != nil {
+		return t, err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		// Read the workflow again inside the transaction so statuses
+		// removed by a concurrent update are reset as well.
+		tasksList, err := s.TasksLists.GetUserTasksList(ctx, tlid, current.UserID)
+		if err != nil {
+			return err
+		}
+
+		t, err = s.TasksLists.setStatuses(ctx, tlid, current.UserID, statuses)
+		if err != nil {
+			return err
+		}
+
+		return s.Tasks.resetStatuses(ctx, tlid, models.StaleStatuses(tasksList.Workflow(), statuses))
+	})
+
+	return t, err
+}
+
+// GetBoard returns the tasks of a list the user can see grouped by the
+// statuses of the list.
+func (s *Services) GetBoard(ctx context.Context, tlid string, uid string) (board models.Board, err error) {
+	tasksList, err := s.AuthorizeTasksList(ctx, tlid, uid, models.RoleViewer)
+	if err != nil {
+		return board, err
+	}
+
+	tasks, err := s.Tasks.getListTasks(ctx, tlid, tasksList.UserID)
+	if err != nil {
+		return board, err
+	}
+
+	if err := s.AttachBlocked(ctx, tasks); err != nil {
+		return board, err
+	}
+
+	return models.BuildBoard(tasksList, tasks), nil
+}
+
+// AttachStatuses sets the status of every task to its column in the
+// workflow of its list. Tasks keep the status they were stored with, which
+// may be empty or no longer in the workflow, say for tasks created before
+// workflows existed, copied, moved or brought back by undo.
+func (s *Services) AttachStatuses(ctx context.Context, tasks []models.Task) error {
+	tlids := make([]string, len(tasks))
+	for i, task := range tasks {
+		tlids[i] = task.ListID
+	}
+
+	tasksLists, err := s.TasksLists.getTasksListsByIDs(ctx, tlids)
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		tasks[i].StatusID = tasksLists[tasks[i].ListID].StatusOf(tasks[i]).ID
+	}
+
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"main/models"
 	"main/utils/logging"
+	"main/utils/mongodb"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -26,9 +28,78 @@ func NewTasksService(db *mongo.Database, logger *logging.Logger) *Tasks {
 	}
 }
 
-func (s Tasks) GetAllUserTasks(ctx context.Context, uid string) (tasks []models.Task, err error) {
-	result, err := s.collection.Find(ctx, bson.M{"user_id": uid})
-	if result.Err() != nil {
+func (s Tasks) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "complete", Value: 1}, {Key: "due_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "list_id", Value: 1}, {Key: "position", Value: 1}}},
+		{Keys: bson.D{{Key: "series_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "priority", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "starred", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		{Keys: bson.D{{Key: "assignee_id", Value: 1}, {Key: "complete", Value: 1}}},
+	})
+
+	return err
+}
+
+func (s Tasks) GetAllUserTasks(ctx context.Context, scope models.TasksScope, filter models.TasksFilter) (tasks []models.Task, err error) {
+	query := bson.M{"deleted_at": nil}
+	if len(filter.Tags) > 0 {
+		if filter.TagsMatch == "all" {
+			query["tags"] = bson.M{"$all": filter.Tags}
+		} else {
+			query["tags"] = bson.M{"$in": filter.Tags}
+		}
+	}
+	filterAssignee(query, scope, filter.Assignee)
+
+	result, err := s.collection.Find(ctx, scoped(scope, query), options.Find().SetSort(tasksSort(filter.Sort)))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+// filterAssignee narrows query to the tasks assigned to assignee, which is
+// "me", "none", a user id or empty for everyone.
+func filterAssignee(query bson.M, scope models.TasksScope, assignee string) {
+	switch assignee {
+	case "":
+	case "me":
+		query["assignee_id"] = scope.UserID
+	case "none":
+		query["assignee_id"] = bson.M{"$in": bson.A{"", nil}}
+	default:
+		query["assignee_id"] = assignee
+	}
+}
+
+func (s Tasks) GetUserStarredTasks(ctx context.Context, scope models.TasksScope, assignee string) (tasks []models.Task, err error) {
+	query := bson.M{"starred": true, "deleted_at": nil}
+	filterAssignee(query, scope, assignee)
+	query = scoped(scope, query)
+
+	result, err := s.collection.Find(ctx, query, options.Find().SetSort(tasksSort("priority")))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+// GetUserAssignedTasks returns the open tasks assigned to the user of scope,
+// whichever list they belong to.
+func (s Tasks) GetUserAssignedTasks(ctx context.Context, scope models.TasksScope) (tasks []models.Task, err error) {
+	query := scoped(scope, bson.M{"assignee_id": scope.UserID, "complete": false, "deleted_at": nil})
+
+	result, err := s.collection.Find(ctx, query, options.Find().SetSort(tasksSort("due")))
+	if err != nil {
 		return tasks, err
 	}
 
@@ -37,7 +108,133 @@ func (s Tasks) GetAllUserTasks(ctx context.Context, uid string) (tasks []models.
 	return tasks, err
 }
 
+// getAnyTask finds a task whether it is in the trash or not.
+func (s Tasks) getAnyTask(ctx context.Context, tid string) (task models.Task, err error) {
+	toid, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return task, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": toid}).Decode(&task)
+
+	return task, err
+}
+
+// setTaskState sets the fields of current a user can change back to their
+// values in state. The position is only restored along with the list, so
+// reordering the list in the meantime is kept.
+func (s Tasks) setTaskState(ctx context.Context, current models.Task, state models.Task) error {
+	raw, err := bson.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	var set bson.M
+	if err := bson.Unmarshal(raw, &set); err != nil {
+		return err
+	}
+
+	for _, field := range []string{"_id", "user_id", "series_id", "occurrence", "comments_count", "deleted_at", "CreatedAt"} {
+		delete(set, field)
+	}
+	if state.ListID == current.ListID {
+		delete(set, "position")
+	}
+	set["UpdatedAt"] = time.Now()
+
+	toid, err := primitive.ObjectIDFromHex(current.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": toid, "user_id": current.UserID, "deleted_at": nil}, bson.M{"$set": set})
+
+	return err
+}
+
+// incCommentsCount keeps the comment count of a task in step with its
+// comments.
+func (s Tasks) incCommentsCount(ctx context.Context, tid string, delta int) error {
+	toid, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": toid}, bson.M{"$inc": bson.M{"comments_count": delta}})
+
+	return err
+}
+
+// unassignListMember clears the assignee of the tasks of the list that are
+// assigned to the member.
+func (s Tasks) unassignListMember(ctx context.Context, tlid string, uid string) error {
+	_, err := s.collection.UpdateMany(ctx, bson.M{"list_id": tlid, "assignee_id": uid}, bson.M{"$set": bson.M{"assignee_id": ""}})
+
+	return err
+}
+
+// scoped restricts query to the tasks covered by scope.
+func scoped(scope models.TasksScope, query bson.M) bson.M {
+	lists := bson.M{}
+	if len(scope.SharedListIDs) > 0 {
+		lists["$or"] = bson.A{bson.M{"user_id": scope.UserID}, bson.M{"list_id": bson.M{"$in": scope.SharedListIDs}}}
+	} else {
+		lists["user_id"] = scope.UserID
+	}
+
+	if len(scope.ExcludeListIDs) > 0 {
+		lists["list_id"] = bson.M{"$nin": scope.ExcludeListIDs}
+	}
+
+	return bson.M{"$and": bson.A{lists, query}}
+}
+
+func tasksSort(sort string) bson.D {
+	switch sort {
+	case "priority":
+		return bson.D{{Key: "priority", Value: -1}, {Key: "due_at", Value: 1}, {Key: "CreatedAt", Value: 1}}
+	case "due":
+		return bson.D{{Key: "due_at", Value: 1}, {Key: "CreatedAt", Value: 1}}
+	case "created":
+		return bson.D{{Key: "CreatedAt", Value: 1}}
+	default:
+		return positionOrder
+	}
+}
+
+func (s Tasks) getTask(ctx context.Context, tid string) (task models.Task, err error) {
+	toid, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return task, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": toid, "deleted_at": nil}).Decode(&task)
+
+	return task, err
+}
+
+func (s Tasks) GetUserTask(ctx context.Context, tid string, uid string) (task models.Task, err error) {
+	toid, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return task, err
+	}
+
+	result := s.collection.FindOne(ctx, bson.M{"_id": toid, "user_id": uid, "deleted_at": nil})
+	if result.Err() != nil {
+		return task, result.Err()
+	}
+
+	err = result.Decode(&task)
+
+	return task, err
+}
+
 func (s Tasks) AddTask(ctx context.Context, task *models.CreateTaskDTO) (u models.Task, err error) {
+	task.Position, err = nextPosition(ctx, s.collection, bson.M{"user_id": task.UserID, "list_id": task.ListID})
+	if err != nil {
+		return u, err
+	}
+
 	result, err := s.collection.InsertOne(ctx, task)
 	if err != nil {
 		return u, err
@@ -51,14 +248,259 @@ func (s Tasks) AddTask(ctx context.Context, task *models.CreateTaskDTO) (u model
 	return *task.Build(oid.Hex()), nil
 }
 
-func (s Tasks) UpdateTask(ctx context.Context, tid string, uid string, task *models.UpdateTaskDTO) (t *models.Task, err error) {
+// UpdateTask applies the update and, when it completes a recurring task,
+// creates the next occurrence of the series in the same transaction. next
+// is that occurrence, or nil when none was created.
+func (s Tasks) UpdateTask(ctx context.Context, tid string, uid string, task *models.UpdateTaskDTO) (t *models.Task, next *models.Task, err error) {
+	toid, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return t, next, err
+	}
+
+	filter := bson.M{"_id": toid, "user_id": uid, "deleted_at": nil}
+
+	err = mongodb.WithTransaction(ctx, s.collection.Database(), func(sc mongo.SessionContext) error {
+		var before models.Task
+		if err := s.collection.FindOne(sc, filter).Decode(&before); err != nil {
+			return err
+		}
+
+		result := s.collection.FindOneAndUpdate(
+			sc, filter, bson.M{"$set": task},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		)
+		if result.Err() != nil {
+			return result.Err()
+		}
+
+		if err := result.Decode(&t); err != nil {
+			return err
+		}
+
+		if before.ListID != t.ListID {
+			t.Position, err = nextPosition(sc, s.collection, bson.M{"user_id": uid, "list_id": t.ListID, "_id": bson.M{"$ne": toid}})
+			if err != nil {
+				return err
+			}
+
+			_, err = s.collection.UpdateOne(sc, bson.M{"_id": toid}, bson.M{"$set": bson.M{"position": t.Position}})
+			if err != nil {
+				return err
+			}
+		}
+
+		if before.Complete || !t.Complete {
+			return nil
+		}
+
+		occurrence, ok, err := t.NextOccurrence(time.Now())
+		if err != nil || !ok {
+			return err
+		}
+
+		occurrence.Position, err = nextPosition(sc, s.collection, bson.M{"user_id": uid, "list_id": occurrence.ListID})
+		if err != nil {
+			return err
+		}
+
+		inserted, err := s.collection.InsertOne(sc, occurrence)
+		if err != nil {
+			return err
+		}
+
+		oid, ok := inserted.InsertedID.(primitive.ObjectID)
+		if !ok {
+			return fmt.Errorf("failed convert objectid to hex")
+		}
+
+		next = occurrence.Build(oid.Hex())
+
+		return nil
+	})
+
+	return t, next, err
+}
+
+// DeleteTask moves the task to the trash.
+func (s Tasks) DeleteTask(ctx context.Context, tid string, uid string) (id string, err error) {
+	toid, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return tid, err
+	}
+
+	result, err := s.collection.UpdateOne(
+		ctx, bson.M{"_id": toid, "user_id": uid, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": time.Now()}},
+	)
+	if err != nil {
+		return tid, err
+	}
+
+	if result.MatchedCount == 0 {
+		return "", fmt.Errorf("task not found")
+	}
+
+	return tid, err
+}
+
+// DeleteAllTask moves every task of the user to the trash.
+func (s Tasks) DeleteAllTask(ctx context.Context, uid string) (err error) {
+	result, err := s.collection.UpdateMany(
+		ctx, bson.M{"user_id": uid, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("tasks not found")
+	}
+
+	return err
+}
+
+func (s Tasks) GetUserTrashedTasks(ctx context.Context, uid string) (tasks []models.Task, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid, "deleted_at": bson.M{"$ne": nil}},
+		options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}}))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+// RestoreTask takes the task out of the trash. The returned task still
+// carries the time it was deleted at.
+func (s Tasks) RestoreTask(ctx context.Context, tid string, uid string) (t *models.Task, err error) {
 	toid, err := primitive.ObjectIDFromHex(tid)
 	if err != nil {
 		return t, err
 	}
 
 	result := s.collection.FindOneAndUpdate(
-		ctx, bson.M{"_id": toid, "user_id": uid}, bson.M{"$set": task},
+		ctx, bson.M{"_id": toid, "user_id": uid, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$set": bson.M{"deleted_at": nil}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	)
+	if result.Err() != nil {
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
+
+// purge permanently removes trashed tasks matching filter and returns their
+// ids.
+func (s Tasks) purge(ctx context.Context, filter bson.M) (tids []string, err error) {
+	if _, ok := filter["deleted_at"]; !ok {
+		filter["deleted_at"] = bson.M{"$ne": nil}
+	}
+
+	result, err := s.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return tids, err
+	}
+
+	var docs []positioned
+	if err := result.All(ctx, &docs); err != nil {
+		return tids, err
+	}
+
+	if len(docs) == 0 {
+		return tids, nil
+	}
+
+	oids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		oids[i] = doc.ID
+		tids = append(tids, doc.ID.Hex())
+	}
+
+	_, err = s.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": oids}})
+
+	return tids, err
+}
+
+// GetUserTasksDueBetween returns incomplete tasks due in [from, to), where
+// from and to are day boundaries of the request timezone. All-day tasks are
+// due between their dates.
+func (s Tasks) GetUserTasksDueBetween(ctx context.Context, scope models.TasksScope, assignee string, from, to time.Time) (tasks []models.Task, err error) {
+	query := bson.M{
+		"complete":   false,
+		"deleted_at": nil,
+		"$or": bson.A{
+			bson.M{"due_all_day": false, "due_at": bson.M{"$gte": from, "$lt": to}},
+			bson.M{"due_all_day": true, "due_at": bson.M{"$gte": models.DateOf(from), "$lt": models.DateOf(to)}},
+		},
+	}
+	filterAssignee(query, scope, assignee)
+
+	result, err := s.collection.Find(ctx, scoped(scope, query), options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}}))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+// GetUserOverdueTasks returns incomplete tasks whose due time has passed.
+// All-day tasks become overdue only once the day they are due on is over
+// in the timezone of today.
+func (s Tasks) GetUserOverdueTasks(ctx context.Context, scope models.TasksScope, assignee string, now, today time.Time) (tasks []models.Task, err error) {
+	query := bson.M{
+		"complete":   false,
+		"deleted_at": nil,
+		"$or": bson.A{
+			bson.M{"due_all_day": false, "due_at": bson.M{"$lt": now}},
+			bson.M{"due_all_day": true, "due_at": bson.M{"$lt": models.DateOf(today)}},
+		},
+	}
+	filterAssignee(query, scope, assignee)
+
+	result, err := s.collection.Find(ctx, scoped(scope, query), options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}}))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+func (s Tasks) RemoveTag(ctx context.Context, tgid string, uid string) error {
+	_, err := s.collection.UpdateMany(ctx, bson.M{"user_id": uid, "tags": tgid}, bson.M{"$pull": bson.M{"tags": tgid}})
+
+	return err
+}
+
+func (s Tasks) AddSub(ctx context.Context, tid string, uid string, sub *models.CreateSubTaskRB) (t *models.Task, err error) {
+	task, err := s.GetUserTask(ctx, tid, uid)
+	if err != nil {
+		return t, err
+	}
+
+	position := 0
+	for _, existing := range task.Subs {
+		if existing.Position >= position {
+			position = existing.Position + 1
+		}
+	}
+
+	toid, _ := primitive.ObjectIDFromHex(tid)
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": toid, "user_id": uid, "deleted_at": nil},
+		bson.M{
+			"$push": bson.M{"subs": sub.Build(position)},
+			"$set":  bson.M{"UpdatedAt": time.Now()},
+		},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	)
 	if result.Err() != nil {
@@ -70,33 +512,692 @@ func (s Tasks) UpdateTask(ctx context.Context, tid string, uid string, task *mod
 	return t, err
 }
 
-func (s Tasks) DeleteTask(ctx context.Context, tid string, uid string) (id string, err error) {
+// UpdateSub changes a single subtask in place with a positional update, so
+// concurrent edits of other subtasks are not overwritten.
+func (s Tasks) UpdateSub(ctx context.Context, tid string, sid string, uid string, sub *models.UpdateSubTaskRB) (t *models.Task, err error) {
 	toid, err := primitive.ObjectIDFromHex(tid)
 	if err != nil {
-		return tid, err
+		return t, err
+	}
+
+	set := bson.M{"UpdatedAt": time.Now()}
+	if sub.Title != nil {
+		set["subs.$.title"] = *sub.Title
 	}
+	if sub.Complete != nil {
+		set["subs.$.complete"] = *sub.Complete
+	}
+	if sub.Position != nil {
+		set["subs.$.position"] = *sub.Position
+	}
+
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": toid, "user_id": uid, "deleted_at": nil, "subs.id": sid}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return t, fmt.Errorf("subtask not found")
+		}
+		return t, result.Err()
+	}
+
+	err = result.Decode(&t)
+
+	return t, err
+}
 
-	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": toid, "user_id": uid})
+func (s Tasks) DeleteSub(ctx context.Context, tid string, sid string, uid string) (t *models.Task, err error) {
+	toid, err := primitive.ObjectIDFromHex(tid)
 	if err != nil {
-		return tid, err
+		return t, err
 	}
 
-	if result.DeletedCount == 0 {
-		return "", fmt.Errorf("task not found")
+	result := s.collection.FindOneAndUpdate(
+		ctx, bson.M{"_id": toid, "user_id": uid, "deleted_at": nil, "subs.id": sid},
+		bson.M{
+			"$pull": bson.M{"subs": bson.M{"id": sid}},
+			"$set":  bson.M{"UpdatedAt": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return t, fmt.Errorf("subtask not found")
+		}
+		return t, result.Err()
 	}
 
-	return tid, err
+	err = result.Decode(&t)
+
+	return t, err
 }
 
-func (s Tasks) DeleteAllTask(ctx context.Context, uid string) (err error) {
-	result, err := s.collection.DeleteMany(ctx, bson.M{"user_id": uid})
+// backfillSubIDs gives ids and positions to subtasks created before they
+// became addressable.
+func (s Tasks) backfillSubIDs(ctx context.Context) error {
+	result, err := s.collection.Find(ctx, bson.M{"subs": bson.M{"$elemMatch": bson.M{"id": bson.M{"$exists": false}}}})
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return fmt.Errorf("tasks not found")
+	var tasks []models.Task
+	if err := result.All(ctx, &tasks); err != nil {
+		return err
 	}
 
+	for _, task := range tasks {
+		for i := range task.Subs {
+			if task.Subs[i].ID == "" {
+				task.Subs[i].ID = primitive.NewObjectID().Hex()
+			}
+			task.Subs[i].Position = i
+		}
+
+		toid, err := primitive.ObjectIDFromHex(task.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.collection.UpdateOne(ctx, bson.M{"_id": toid}, bson.M{"$set": bson.M{"subs": task.Subs}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MoveTask places the task between two neighbours of its list.
+func (s Tasks) MoveTask(ctx context.Context, tid string, uid string, move *models.MoveRB) (t *models.Task, err error) {
+	task, err := s.GetUserTask(ctx, tid, uid)
+	if err != nil {
+		return t, err
+	}
+
+	toid, _ := primitive.ObjectIDFromHex(tid)
+
+	err = movePosition(ctx, s.collection, toid, bson.M{"user_id": uid, "list_id": task.ListID}, move.After, move.Before)
+	if err != nil {
+		return t, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": toid}).Decode(&t)
+
+	return t, err
+}
+
+func (s Tasks) backfillPositions(ctx context.Context) error {
+	listIDs, err := s.collection.Distinct(ctx, "list_id", bson.M{})
+	if err != nil {
+		return err
+	}
+
+	for _, lid := range listIDs {
+		if err := rebalancePositions(ctx, s.collection, bson.M{"list_id": lid}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getUserTasksByIDs returns the tasks in the order of tids and fails unless
+// all of them belong to the user.
+func (s Tasks) getUserTasksByIDs(ctx context.Context, tids []string, uid string) (tasks []models.Task, err error) {
+	oids := make([]primitive.ObjectID, len(tids))
+	for i, tid := range tids {
+		oids[i], err = primitive.ObjectIDFromHex(tid)
+		if err != nil {
+			return tasks, err
+		}
+	}
+
+	result, err := s.collection.Find(ctx, bson.M{"_id": bson.M{"$in": oids}, "user_id": uid, "deleted_at": nil})
+	if err != nil {
+		return tasks, err
+	}
+
+	var found []models.Task
+	if err := result.All(ctx, &found); err != nil {
+		return tasks, err
+	}
+
+	byID := map[string]models.Task{}
+	for _, task := range found {
+		byID[task.ID] = task
+	}
+
+	seen := map[string]bool{}
+	for _, tid := range tids {
+		task, ok := byID[tid]
+		if !ok {
+			return nil, fmt.Errorf("task %s not found", tid)
+		}
+		if !seen[tid] {
+			seen[tid] = true
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+// getScopedTasksByIDs returns the tasks of tids covered by scope, leaving
+// out the ones in the trash.
+func (s Tasks) getScopedTasksByIDs(ctx context.Context, scope models.TasksScope, tids []string) (tasks []models.Task, err error) {
+	tasks = []models.Task{}
+	if len(tids) == 0 {
+		return tasks, nil
+	}
+
+	oids := make([]primitive.ObjectID, len(tids))
+	for i, tid := range tids {
+		oids[i], err = primitive.ObjectIDFromHex(tid)
+		if err != nil {
+			return tasks, err
+		}
+	}
+
+	result, err := s.collection.Find(ctx, scoped(scope, bson.M{"_id": bson.M{"$in": oids}, "deleted_at": nil}), options.Find().SetSort(positionOrder))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+// getTaskOwners returns the owners of the tasks, in the trash or not, each
+// once.
+func (s Tasks) getTaskOwners(ctx context.Context, tids []string) ([]string, error) {
+	if len(tids) == 0 {
+		return nil, nil
+	}
+
+	oids := make([]primitive.ObjectID, len(tids))
+	for i, tid := range tids {
+		oid, err := primitive.ObjectIDFromHex(tid)
+		if err != nil {
+			return nil, err
+		}
+		oids[i] = oid
+	}
+
+	values, err := s.collection.Distinct(ctx, "user_id", bson.M{"_id": bson.M{"$in": oids}})
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]string, 0, len(values))
+	for _, value := range values {
+		if owner, ok := value.(string); ok {
+			owners = append(owners, owner)
+		}
+	}
+
+	return owners, nil
+}
+
+// getOpenTaskIDs returns which of tids are neither complete nor in the
+// trash.
+func (s Tasks) getOpenTaskIDs(ctx context.Context, tids []string) (open map[string]bool, err error) {
+	open = map[string]bool{}
+	if len(tids) == 0 {
+		return open, nil
+	}
+
+	oids := make([]primitive.ObjectID, len(tids))
+	for i, tid := range tids {
+		oids[i], err = primitive.ObjectIDFromHex(tid)
+		if err != nil {
+			return open, err
+		}
+	}
+
+	result, err := s.collection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": oids}, "complete": false, "deleted_at": nil},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return open, err
+	}
+
+	var tasks []models.Task
+	if err := result.All(ctx, &tasks); err != nil {
+		return open, err
+	}
+
+	for _, task := range tasks {
+		open[task.ID] = true
+	}
+
+	return open, nil
+}
+
+// moveToList appends the tasks to the end of the list keeping their order.
+func (s Tasks) moveToList(ctx context.Context, tasks []models.Task, uid string, lid string) ([]models.Task, error) {
+	for i, task := range tasks {
+		toid, _ := primitive.ObjectIDFromHex(task.ID)
+
+		key, err := nextPosition(ctx, s.collection, bson.M{"user_id": uid, "list_id": lid, "_id": bson.M{"$ne": toid}})
+		if err != nil {
+			return nil, err
+		}
+
+		result := s.collection.FindOneAndUpdate(
+			ctx, bson.M{"_id": toid, "user_id": uid},
+			bson.M{"$set": bson.M{"list_id": lid, "position": key, "UpdatedAt": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		)
+
+		var moved models.Task
+		if err := result.Decode(&moved); err != nil {
+			return nil, err
+		}
+		tasks[i] = moved
+	}
+
+	return tasks, nil
+}
+
+// BulkMoveTasks moves the tasks to another list of the user. Either all of
+// them are moved or none.
+func (s *Services) BulkMoveTasks(ctx context.Context, tids []string, uid string, lid string) (tasks []models.Task, err error) {
+	err = s.Transaction(ctx, func(sc context.Context) error {
+		found, tasksList, err := s.authorizeBulk(sc, tids, uid, lid, models.RoleEditor)
+		if err != nil {
+			return err
+		}
+		owner := tasksList.UserID
+
+		for _, task := range found {
+			if err := s.checkAssignee(sc, tasksList, task.AssigneeID); err != nil {
+				return fmt.Errorf("task %s: %s", task.ID, err.Error())
+			}
+		}
+
+		before := append([]models.Task(nil), found...)
+
+		tasks, err = s.Tasks.moveToList(sc, found, owner, lid)
+		if err != nil {
+			return err
+		}
+
+		if err := s.invalidateStats(sc, owner, append(taskListIDs(before...), lid)...); err != nil {
+			return err
+		}
+
+		return s.recordOperation(sc, uid, before, tasks)
+	})
+
+	return tasks, err
+}
+
+// BulkCopyTasks copies the tasks into another list of the user. Either all
+// copies are created or none.
+func (s *Services) BulkCopyTasks(ctx context.Context, tids []string, uid string, lid string) (tasks []models.Task, err error) {
+	err = s.Transaction(ctx, func(sc context.Context) error {
+		tasks = nil
+
+		found, tasksList, err := s.authorizeBulk(sc, tids, uid, lid, models.RoleViewer)
+		if err != nil {
+			return err
+		}
+		owner := tasksList.UserID
+
+		for _, task := range found {
+			dto := task.Copy(lid)
+			dto.StatusID = tasksList.StatusOf(task).ID
+
+			copied, err := s.Tasks.AddTask(sc, dto)
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, copied)
+		}
+
+		if err := s.invalidateStats(sc, owner, lid); err != nil {
+			return err
+		}
+
+		return s.recordOperation(sc, uid, make([]models.Task, len(tasks)), tasks)
+	})
+
+	return tasks, err
+}
+
+// CreateTask adds a task after checking that the user can edit its list and
+// that its tags belong to the list owner, who also owns the task. A task
+// without a list goes to the user's Inbox.
+func (s *Services) CreateTask(ctx context.Context, task *models.CreateTaskDTO) (t models.Task, err error) {
+	uid := task.UserID
+
+	if task.ListID == "" {
+		inbox, err := s.TasksLists.EnsureInbox(ctx, task.UserID)
+		if err != nil {
+			return t, fmt.Errorf("can not find inbox: %s", err.Error())
+		}
+		task.ListID = inbox.ID
+	}
+
+	tasksList, err := s.AuthorizeTasksList(ctx, task.ListID, task.UserID, models.RoleEditor)
+	if err != nil {
+		return t, fmt.Errorf("can not find tasks list: %s", err.Error())
+	}
+	task.UserID = tasksList.UserID
+
+	task.StatusID, task.Complete, err = tasksList.ApplyStatus(task.StatusID, task.Complete, task.StatusID != "")
+	if err != nil {
+		return t, err
+	}
+
+	if err := s.checkAssignee(ctx, tasksList, task.AssigneeID); err != nil {
+		return t, err
+	}
+
+	err = s.Tags.CheckUserTags(ctx, task.Tags, task.UserID)
+	if err != nil {
+		return t, fmt.Errorf("can not find tags: %s", err.Error())
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		t, err = s.Tasks.AddTask(ctx, task)
+		if err != nil {
+			return err
+		}
+
+		if err := s.invalidateStats(ctx, t.UserID, t.ListID); err != nil {
+			return err
+		}
+
+		return s.recordOperation(ctx, uid, []models.Task{{}}, []models.Task{t})
+	})
+
+	return t, err
+}
+
+// UpdateTask updates a task after checking that the user can edit it and
+// its new list, which must have the same owner, and that its tags belong to
+// that owner. Reminders move along with the due date.
+func (s *Services) UpdateTask(ctx context.Context, tid string, uid string, task *models.UpdateTaskDTO) (t *models.Task, err error) {
+	current, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return t, err
+	}
+
+	tasksList, err := s.AuthorizeTasksList(ctx, task.ListID, uid, models.RoleEditor)
+	if err != nil {
+		return t, fmt.Errorf("can not find tasks list: %s", err.Error())
+	}
+
+	if tasksList.UserID != current.UserID {
+		return t, fmt.Errorf("tasks can only move between lists of the same owner")
+	}
+
+	// Clients that do not know about statuses leave it empty, which keeps
+	// the current one as long as it agrees with the completion.
+	if task.StatusID == "" {
+		task.StatusID = current.StatusID
+	}
+
+	task.StatusID, task.Complete, err = tasksList.ApplyStatus(task.StatusID, task.Complete, task.StatusID != current.StatusID)
+	if err != nil {
+		return t, err
+	}
+
+	if err := s.checkAssignee(ctx, tasksList, task.AssigneeID); err != nil {
+		return t, err
+	}
+
+	err = s.Tags.CheckUserTags(ctx, task.Tags, current.UserID)
+	if err != nil {
+		return t, fmt.Errorf("can not find tags: %s", err.Error())
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		var next *models.Task
+		t, next, err = s.Tasks.UpdateTask(ctx, tid, current.UserID, task)
+		if err != nil {
+			return err
+		}
+
+		if err := s.invalidateStats(ctx, current.UserID, taskListIDs(current, *t)...); err != nil {
+			return err
+		}
+
+		err = s.Reminders.RescheduleTask(ctx, *t)
+		if err != nil {
+			return fmt.Errorf("can not reschedule reminders: %s", err.Error())
+		}
+
+		before, after := []models.Task{current}, []models.Task{*t}
+		if next != nil {
+			if err := s.Reminders.copyRelativeReminders(ctx, *t, *next); err != nil {
+				return fmt.Errorf("can not copy reminders: %s", err.Error())
+			}
+
+			// Undoing the completion also takes back the occurrence it
+			// spawned.
+			before, after = append(before, models.Task{}), append(after, *next)
+		}
+
+		if err := s.recordOperation(ctx, uid, before, after); err != nil {
+			return err
+		}
+
+		if !current.Complete && t.Complete {
+			return s.notifyUnblocked(ctx, *t)
+		}
+
+		return nil
+	})
+
+	return t, err
+}
+
+func (s Tasks) getUserTasks(ctx context.Context, uid string) (tasks []models.Task, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid, "deleted_at": nil})
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+func (s Tasks) countListTasks(ctx context.Context, lid string, uid string) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"user_id": uid, "list_id": lid, "deleted_at": nil})
+}
+
+func (s Tasks) getListTasks(ctx context.Context, lid string, uid string) (tasks []models.Task, err error) {
+	result, err := s.collection.Find(ctx, bson.M{"user_id": uid, "list_id": lid, "deleted_at": nil}, options.Find().SetSort(positionOrder))
+	if err != nil {
+		return tasks, err
+	}
+
+	err = result.All(ctx, &tasks)
+
+	return tasks, err
+}
+
+// resetStatuses drops the statuses of the list's tasks that are in one of
+// sids, they fall back to the status matching their completion.
+func (s Tasks) resetStatuses(ctx context.Context, lid string, sids []string) error {
+	if len(sids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.UpdateMany(
+		ctx, bson.M{"list_id": lid, "status_id": bson.M{"$in": sids}},
+		bson.M{"$set": bson.M{"status_id": ""}},
+	)
+
+	return err
+}
+
+// trashListTasks moves the tasks of a list to the trash, stamping them with
+// the deletion time of the list so they can be restored together.
+func (s Tasks) trashListTasks(ctx context.Context, lid string, uid string, deletedAt time.Time) error {
+	_, err := s.collection.UpdateMany(
+		ctx, bson.M{"user_id": uid, "list_id": lid, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": deletedAt}},
+	)
+
 	return err
 }
+
+// restoreListTasks takes the tasks trashed with their list out of the trash
+// and returns their ids.
+func (s Tasks) restoreListTasks(ctx context.Context, lid string, uid string, deletedAt time.Time) (tids []string, err error) {
+	filter := bson.M{"user_id": uid, "list_id": lid, "deleted_at": deletedAt}
+
+	result, err := s.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return tids, err
+	}
+
+	var docs []positioned
+	if err := result.All(ctx, &docs); err != nil {
+		return tids, err
+	}
+
+	tids = make([]string, len(docs))
+	for i, doc := range docs {
+		tids[i] = doc.ID.Hex()
+	}
+
+	_, err = s.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"deleted_at": nil}})
+
+	return tids, err
+}
+
+// DeleteTask moves the task to the trash of its owner when the user can
+// edit it.
+func (s *Services) DeleteTask(ctx context.Context, tid string, uid string) (id string, err error) {
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		id, err = s.Tasks.DeleteTask(ctx, tid, task.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.invalidateStats(ctx, task.UserID, task.ListID); err != nil {
+			return err
+		}
+
+		return s.recordOperation(ctx, uid, []models.Task{task}, []models.Task{{}})
+	})
+
+	return id, err
+}
+
+// DeleteAllTasks moves all tasks the user owns to the trash.
+func (s *Services) DeleteAllTasks(ctx context.Context, uid string) error {
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		tasks, err := s.Tasks.getUserTasks(ctx, uid)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Tasks.DeleteAllTask(ctx, uid); err != nil {
+			return err
+		}
+
+		if err := s.invalidateStats(ctx, uid, taskListIDs(tasks...)...); err != nil {
+			return err
+		}
+
+		return s.recordOperation(ctx, uid, tasks, make([]models.Task, len(tasks)))
+	})
+}
+
+// AddSub adds a subtask to a task the user can edit.
+func (s *Services) AddSub(ctx context.Context, tid string, uid string, sub *models.CreateSubTaskRB) (t *models.Task, err error) {
+	current, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return t, err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		t, err = s.Tasks.AddSub(ctx, tid, current.UserID, sub)
+		if err != nil {
+			return err
+		}
+
+		return s.recordOperation(ctx, uid, []models.Task{current}, []models.Task{*t})
+	})
+
+	return t, err
+}
+
+func (s *Services) UpdateSub(ctx context.Context, tid string, sid string, uid string, sub *models.UpdateSubTaskRB) (t *models.Task, err error) {
+	current, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return t, err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		t, err = s.Tasks.UpdateSub(ctx, tid, sid, current.UserID, sub)
+		if err != nil {
+			return err
+		}
+
+		return s.recordOperation(ctx, uid, []models.Task{current}, []models.Task{*t})
+	})
+
+	return t, err
+}
+
+func (s *Services) DeleteSub(ctx context.Context, tid string, sid string, uid string) (t *models.Task, err error) {
+	current, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return t, err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		t, err = s.Tasks.DeleteSub(ctx, tid, sid, current.UserID)
+		if err != nil {
+			return err
+		}
+
+		return s.recordOperation(ctx, uid, []models.Task{current}, []models.Task{*t})
+	})
+
+	return t, err
+}
+
+// authorizeBulk returns the tasks for a bulk operation into the list lid
+// with that list. The user needs role on every source list and editor on
+// the target, and all lists must have the same owner.
+func (s *Services) authorizeBulk(ctx context.Context, tids []string, uid string, lid string, role string) (tasks []models.Task, tasksList models.TasksList, err error) {
+	tasksList, err = s.AuthorizeTasksList(ctx, lid, uid, models.RoleEditor)
+	if err != nil {
+		return tasks, tasksList, fmt.Errorf("can not find tasks list: %s", err.Error())
+	}
+
+	tasks, err = s.Tasks.getUserTasksByIDs(ctx, tids, tasksList.UserID)
+	if err != nil {
+		return tasks, tasksList, err
+	}
+
+	checked := map[string]bool{}
+	for _, task := range tasks {
+		if checked[task.ListID] {
+			continue
+		}
+
+		if _, err := s.AuthorizeTasksList(ctx, task.ListID, uid, role); err != nil {
+			return tasks, tasksList, fmt.Errorf("task %s not found", task.ID)
+		}
+		checked[task.ListID] = true
+	}
+
+	return tasks, tasksList, nil
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"main/utils/logging"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTimerRunning and ErrNoTimer are returned when starting a timer while
+// another one runs and when stopping a timer that does not run.
+var (
+	ErrTimerRunning = fmt.Errorf("a timer is already running")
+	ErrNoTimer      = fmt.Errorf("no timer is running for this task")
+)
+
+// releaseTimerScript drops the running timer of a user only while it is
+// still the entry that was stopped.
+var releaseTimerScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// TimeEntries stores tracked time in MongoDB. The running timer of every
+// user is claimed in Redis, which makes sure a user never runs two timers
+// at once. Without a Redis client timers can not be started.
+type TimeEntries struct {
+	collection *mongo.Collection
+	redis      *redis.Client
+	logger     *logging.Logger
+}
+
+func NewTimeEntriesService(db *mongo.Database, logger *logging.Logger) *TimeEntries {
+	timeEntriesCollection := db.Collection("time_entries")
+
+	return &TimeEntries{
+		collection: timeEntriesCollection,
+		logger:     logger,
+	}
+}
+
+func (s TimeEntries) CreateIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "started_at", Value: 1}}},
+		{Keys: bson.D{{Key: "task_id", Value: 1}}},
+	})
+
+	return err
+}
+
+func (s TimeEntries) timerKey(uid string) string {
+	return fmt.Sprintf("timers:running:%s", uid)
+}
+
+// claimTimer marks eid as the running timer of the user. Entries are stored
+// before they are claimed, so a claim left by an entry that no longer
+// exists, say of a purged task, is stale and taken over. So is the claim of
+// a stopped entry that could not be released.
+func (s TimeEntries) claimTimer(ctx context.Context, uid string, eid string) error {
+	if s.redis == nil {
+		return fmt.Errorf("time tracking is not available")
+	}
+
+	claimed, err := s.redis.SetNX(ctx, s.timerKey(uid), eid, 0).Result()
+	if err != nil || claimed {
+		return err
+	}
+
+	running, err := s.redis.Get(ctx, s.timerKey(uid)).Result()
+	if err == redis.Nil {
+		return s.claimTimer(ctx, uid, eid)
+	}
+	if err != nil {
+		return err
+	}
+
+	entry, err := s.getEntry(ctx, running, uid)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if err == nil && entry.StoppedAt == nil {
+		return ErrTimerRunning
+	}
+
+	if err := s.releaseTimer(ctx, uid, running); err != nil {
+		return err
+	}
+
+	return s.claimTimer(ctx, uid, eid)
+}
+
+// runningTimer returns the id of the entry of the user's running timer.
+func (s TimeEntries) runningTimer(ctx context.Context, uid string) (eid string, err error) {
+	if s.redis == nil {
+		return "", fmt.Errorf("time tracking is not available")
+	}
+
+	eid, err = s.redis.Get(ctx, s.timerKey(uid)).Result()
+	if err == redis.Nil {
+		return "", ErrNoTimer
+	}
+
+	return eid, err
+}
+
+func (s TimeEntries) releaseTimer(ctx context.Context, uid string, eid string) error {
+	return releaseTimerScript.Run(ctx, s.redis, []string{s.timerKey(uid)}, eid).Err()
+}
+
+func (s TimeEntries) getEntry(ctx context.Context, eid string, uid string) (entry models.TimeEntry, err error) {
+	eoid, err := primitive.ObjectIDFromHex(eid)
+	if err != nil {
+		return entry, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": eoid, "user_id": uid}).Decode(&entry)
+
+	return entry, err
+}
+
+func (s TimeEntries) addEntry(ctx context.Context, entry *models.TimeEntry) error {
+	eoid, err := primitive.ObjectIDFromHex(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.InsertOne(ctx, bson.M{
+		"_id":        eoid,
+		"user_id":    entry.UserID,
+		"task_id":    entry.TaskID,
+		"list_id":    entry.ListID,
+		"tags":       entry.Tags,
+		"started_at": entry.StartedAt,
+		"stopped_at": entry.StoppedAt,
+		"seconds":    entry.Seconds,
+		"CreatedAt":  entry.CreatedAt,
+	})
+
+	return err
+}
+
+func (s TimeEntries) deleteEntry(ctx context.Context, eid string) error {
+	eoid, err := primitive.ObjectIDFromHex(eid)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.DeleteOne(ctx, bson.M{"_id": eoid})
+
+	return err
+}
+
+func (s TimeEntries) stopEntry(ctx context.Context, entry models.TimeEntry) error {
+	eoid, err := primitive.ObjectIDFromHex(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": eoid}, bson.M{"$set": bson.M{
+		"stopped_at": entry.StoppedAt,
+		"seconds":    entry.Seconds,
+	}})
+
+	return err
+}
+
+func (s TimeEntries) deleteTasksTimeEntries(ctx context.Context, tids []string) error {
+	if len(tids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": tids}})
+
+	return err
+}
+
+// sumTime adds up the stopped entries matching match, grouped by key.
+func (s TimeEntries) sumTime(ctx context.Context, match bson.M, unwind string, key interface{}) (groups []models.TimeReportGroup, err error) {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	if unwind != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: bson.M{
+			"path":                       unwind,
+			"preserveNullAndEmptyArrays": true,
+		}}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: bson.M{
+		"_id":     bson.M{"$ifNull": bson.A{key, ""}},
+		"seconds": bson.M{"$sum": "$seconds"},
+		"entries": bson.M{"$sum": 1},
+	}}})
+
+	result, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return groups, err
+	}
+
+	groups = []models.TimeReportGroup{}
+	err = result.All(ctx, &groups)
+
+	return groups, err
+}
+
+// UseTimers keeps the running timers of users in Redis.
+func (s *Services) UseTimers(rdb *redis.Client) {
+	s.TimeEntries.redis = rdb
+}
+
+// StartTimer starts tracking time on a task the user can edit. Only one
+// timer of a user runs at a time, starting another fails with
+// ErrTimerRunning.
+func (s *Services) StartTimer(ctx context.Context, tid string, uid string) (entry models.TimeEntry, err error) {
+	task, err := s.AuthorizeTask(ctx, tid, uid, models.RoleEditor)
+	if err != nil {
+		return entry, err
+	}
+
+	entry = *models.NewTimeEntry(primitive.NewObjectID().Hex(), uid, task, time.Now().UTC().Truncate(time.Second))
+
+	if s.TimeEntries.redis == nil {
+		return entry, fmt.Errorf("time tracking is not available")
+	}
+
+	if err := s.TimeEntries.addEntry(ctx, &entry); err != nil {
+		return entry, err
+	}
+
+	if err := s.TimeEntries.claimTimer(ctx, uid, entry.ID); err != nil {
+		if err := s.TimeEntries.deleteEntry(ctx, entry.ID); err != nil {
+			s.TimeEntries.logger.Errorf("can not delete unclaimed time entry %s: %s", entry.ID, err.Error())
+		}
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// StopTimer stops the user's running timer, which has to be on task tid,
+// and returns the finished entry.
+func (s *Services) StopTimer(ctx context.Context, tid string, uid string) (entry models.TimeEntry, err error) {
+	eid, err := s.TimeEntries.runningTimer(ctx, uid)
+	if err != nil {
+		return entry, err
+	}
+
+	entry, err = s.TimeEntries.getEntry(ctx, eid, uid)
+	if err == mongo.ErrNoDocuments {
+		if err := s.TimeEntries.releaseTimer(ctx, uid, eid); err != nil {
+			return entry, err
+		}
+		return entry, ErrNoTimer
+	}
+	if err != nil {
+		return entry, err
+	}
+
+	// The entry was stopped before but its claim could not be released. It
+	// keeps the time it was stopped with.
+	if entry.StoppedAt != nil {
+		if err := s.TimeEntries.releaseTimer(ctx, uid, eid); err != nil {
+			return entry, err
+		}
+		if entry.TaskID != tid {
+			return entry, ErrNoTimer
+		}
+		return entry, nil
+	}
+
+	if entry.TaskID != tid {
+		return entry, fmt.Errorf("%w, it runs for task %s", ErrNoTimer, entry.TaskID)
+	}
+
+	entry.Stop(time.Now().UTC().Truncate(time.Second))
+
+	if err := s.TimeEntries.stopEntry(ctx, entry); err != nil {
+		return entry, err
+	}
+
+	return entry, s.TimeEntries.releaseTimer(ctx, uid, eid)
+}
+
+// GetTimeReport sums the time the user tracked in the period of query,
+// grouped by list, tag or day. Lists and tags are named as they are now.
+func (s *Services) GetTimeReport(ctx context.Context, uid string, query models.TimeReportQuery) (report models.TimeReport, err error) {
+	report = models.TimeReport{
+		From:    query.From.Format("2006-01-02"),
+		To:      query.To.Format("2006-01-02"),
+		GroupBy: query.GroupBy,
+		Groups:  []models.TimeReportGroup{},
+	}
+
+	match := bson.M{
+		"user_id":    uid,
+		"stopped_at": bson.M{"$ne": nil},
+		"started_at": bson.M{"$gte": query.From.UTC(), "$lt": query.End().UTC()},
+	}
+
+	total, err := s.TimeEntries.sumTime(ctx, match, "", nil)
+	if err != nil {
+		return report, err
+	}
+	if len(total) > 0 {
+		report.TotalSeconds = total[0].Seconds
+	}
+
+	switch query.GroupBy {
+	case models.TimeReportByTag:
+		report.Groups, err = s.TimeEntries.sumTime(ctx, match, "$tags", "$tags")
+	case models.TimeReportByDay:
+		report.Groups, err = s.TimeEntries.sumTime(ctx, match, "", bson.M{"$dateToString": bson.M{
+			"format":   "%Y-%m-%d",
+			"date":     "$started_at",
+			"timezone": query.Location.String(),
+		}})
+	default:
+		report.Groups, err = s.TimeEntries.sumTime(ctx, match, "", "$list_id")
+	}
+	if err != nil {
+		return report, err
+	}
+
+	keys := make([]string, len(report.Groups))
+	for i, group := range report.Groups {
+		keys[i] = group.Key
+	}
+
+	var names map[string]string
+	switch query.GroupBy {
+	case models.TimeReportByTag:
+		names, err = s.Tags.getTagNames(ctx, keys)
+	case models.TimeReportByList:
+		names, err = s.TasksLists.getListNames(ctx, keys)
+	}
+	if err != nil {
+		return report, err
+	}
+
+	for i := range report.Groups {
+		report.Groups[i].Name = report.Groups[i].Key
+		if names != nil {
+			report.Groups[i].Name = names[report.Groups[i].Key]
+		}
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if query.GroupBy == models.TimeReportByDay || report.Groups[i].Seconds == report.Groups[j].Seconds {
+			return report.Groups[i].Key < report.Groups[j].Key
+		}
+		return report.Groups[i].Seconds > report.Groups[j].Seconds
+	})
+
+	return report, nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"main/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (s *Services) GetTrash(ctx context.Context, uid string) (trash models.Trash, err error) {
+	trash.Tasks, err = s.Tasks.GetUserTrashedTasks(ctx, uid)
+	if err != nil {
+		return trash, err
+	}
+
+	if err := s.AttachStatuses(ctx, trash.Tasks); err != nil {
+		return trash, err
+	}
+
+	trash.TasksLists, err = s.TasksLists.GetUserTrashedTasksLists(ctx, uid)
+
+	return trash, err
+}
+
+// RestoreFromTrash restores the task or tasks list with the given id. A task
+// can only be restored while its list is not in the trash, a list brings
+// back the tasks that were trashed together with it.
+func (s *Services) RestoreFromTrash(ctx context.Context, id string, uid string) (restored interface{}, err error) {
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		task, err := s.Tasks.RestoreTask(ctx, id, uid)
+		if err == nil {
+			if _, err := s.TasksLists.GetUserTasksList(ctx, task.ListID, uid); err != nil {
+				return fmt.Errorf("tasks list of the task is not available: %s", err.Error())
+			}
+
+			if err := s.invalidateStats(ctx, uid, task.ListID); err != nil {
+				return err
+			}
+
+			if err := s.Reminders.rearmTasks(ctx, []string{task.ID}, *task.DeletedAt); err != nil {
+				return fmt.Errorf("can not reschedule reminders: %s", err.Error())
+			}
+
+			task.DeletedAt = nil
+			if err := s.Reminders.RescheduleTask(ctx, *task); err != nil {
+				return fmt.Errorf("can not reschedule reminders: %s", err.Error())
+			}
+
+			restored = task
+			return nil
+		}
+		if err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		tasksList, err := s.TasksLists.RestoreTasksList(ctx, id, uid)
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("nothing to restore")
+		}
+		if err != nil {
+			return err
+		}
+
+		tids, err := s.Tasks.restoreListTasks(ctx, id, uid, *tasksList.DeletedAt)
+		if err != nil {
+			return err
+		}
+
+		if err := s.invalidateStats(ctx, uid, id); err != nil {
+			return err
+		}
+
+		if err := s.Reminders.rearmTasks(ctx, tids, *tasksList.DeletedAt); err != nil {
+			return fmt.Errorf("can not reschedule reminders: %s", err.Error())
+		}
+
+		tasksList.DeletedAt = nil
+		restored = tasksList
+		return nil
+	})
+
+	return restored, err
+}
+
+// EmptyTrash permanently deletes everything the user has in the trash.
+// Attachment blobs are deleted once the transaction is committed.
+func (s *Services) EmptyTrash(ctx context.Context, uid string) error {
+	var blobs []string
+	err := s.Transaction(ctx, func(ctx context.Context) (err error) {
+		blobs, err = s.purge(ctx, bson.M{"user_id": uid})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Attachments.deleteBlobs(ctx, blobs)
+
+	return nil
+}
+
+// PurgeTrash permanently deletes items that have been in the trash for
+// longer than retention. Attachment blobs are deleted once the transaction
+// is committed.
+func (s *Services) PurgeTrash(ctx context.Context, retention time.Duration) error {
+	var blobs []string
+	err := s.Transaction(ctx, func(ctx context.Context) (err error) {
+		blobs, err = s.purge(ctx, bson.M{"deleted_at": bson.M{"$lt": time.Now().Add(-retention)}})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Attachments.deleteBlobs(ctx, blobs)
+
+	return nil
+}
+
+// purge returns the keys of the attachment blobs of the purged tasks.
+func (s *Services) purge(ctx context.Context, filter bson.M) (blobs []string, err error) {
+	tasksFilter := bson.M{}
+	for k, v := range filter {
+		tasksFilter[k] = v
+	}
+
+	tids, err := s.Tasks.purge(ctx, tasksFilter)
+	if err != nil {
+		return blobs, err
+	}
+
+	if err := s.Reminders.DeleteTasksReminders(ctx, tids); err != nil {
+		return blobs, err
+	}
+
+	if err := s.Comments.deleteTasksComments(ctx, tids); err != nil {
+		return blobs, err
+	}
+
+	if err := s.Activity.deleteTasksActivity(ctx, tids); err != nil {
+		return blobs, err
+	}
+
+	if err := s.Dependencies.deleteTasksDependencies(ctx, tids); err != nil {
+		return blobs, err
+	}
+
+	if err := s.TimeEntries.deleteTasksTimeEntries(ctx, tids); err != nil {
+		return blobs, err
+	}
+
+	blobs, err = s.Attachments.deleteTasksAttachments(ctx, tids)
+	if err != nil {
+		return blobs, err
+	}
+
+	tlids, err := s.TasksLists.purge(ctx, filter)
+	if err != nil {
+		return blobs, err
+	}
+
+	if err := s.Members.deleteListsMembers(ctx, tlids); err != nil {
+		return blobs, err
+	}
+
+	return blobs, s.ShareLinks.deleteListsShareLinks(ctx, tlids)
+}
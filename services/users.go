@@ -60,3 +60,35 @@ func (s Users) GetAllUsers(ctx context.Context) (users []models.User, err error)
 
 	return users, err
 }
+
+func (s Users) FindUserByID(ctx context.Context, uid string) (u models.User, err error) {
+	uoid, err := primitive.ObjectIDFromHex(uid)
+	if err != nil {
+		return u, err
+	}
+
+	result := s.collection.FindOne(ctx, bson.M{"_id": uoid})
+	if result.Err() != nil {
+		return u, result.Err()
+	}
+
+	err = result.Decode(&u)
+
+	return u, err
+}
+
+// CreateUser registers the user together with their Inbox.
+func (s *Services) CreateUser(ctx context.Context, user *models.User) (uid string, err error) {
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		uid, err = s.Users.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.TasksLists.EnsureInbox(ctx, uid)
+
+		return err
+	})
+
+	return uid, err
+}
@@ -0,0 +1,23 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a blob does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps file contents under opaque keys. Keys are made of path
+// segments separated by "/" and never start with one.
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing any blob
+	// already stored there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns a reader of the blob of the given size. Seeking is cheap,
+	// so the reader can serve Range requests.
+	Open(ctx context.Context, key string, size int64) (io.ReadSeekCloser, error)
+	// Delete removes the blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
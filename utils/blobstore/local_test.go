@@ -0,0 +1,88 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreInvalidKeys(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"", "..", "../outside", "a/../../outside", "/etc/passwd"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) succeeded, want an error", key)
+		}
+		if _, err := store.Open(ctx, key, 1); err == nil {
+			t.Errorf("Open(%q) succeeded, want an error", key)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded, want an error", key)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "outside")); !os.IsNotExist(err) {
+		t.Errorf("a blob was written outside of the store")
+	}
+}
+
+func TestLocalStoreSizeMismatch(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "a/b.txt", strings.NewReader("abc"), 5, ""); err == nil {
+		t.Fatal("Put with a wrong size succeeded, want an error")
+	}
+
+	if _, err := store.Open(ctx, "a/b.txt", 5); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after a failed Put returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "a/b.txt", strings.NewReader("0123456789"), 10, ""); err != nil {
+		t.Fatalf("Put failed: %s", err)
+	}
+
+	blob, err := store.Open(ctx, "a/b.txt", 10)
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	if _, err := blob.Seek(4, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %s", err)
+	}
+	got, err := io.ReadAll(blob)
+	blob.Close()
+	if err != nil || string(got) != "456789" {
+		t.Errorf("read %q, %v after seeking to 4, want %q", got, err, "456789")
+	}
+
+	if err := store.Delete(ctx, "a/b.txt"); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+	if err := store.Delete(ctx, "a/b.txt"); err != nil {
+		t.Errorf("deleting a missing blob returned %s", err)
+	}
+	if _, err := store.Open(ctx, "a/b.txt", 10); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete returned %v, want ErrNotFound", err)
+	}
+}
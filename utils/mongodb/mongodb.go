@@ -36,3 +36,24 @@ func NewClient(ctx context.Context, host, port, username, password, database, au
 
 	return client.Database(database), nil
 }
+
+// WithTransaction runs fn inside a multi-document transaction. When ctx
+// already carries a session fn joins its transaction instead of starting a
+// new one. Transactions require MongoDB to run as a replica set.
+func WithTransaction(ctx context.Context, db *mongo.Database, fn func(sc mongo.SessionContext) error) error {
+	if session := mongo.SessionFromContext(ctx); session != nil {
+		return fn(mongo.NewSessionContext(ctx, session))
+	}
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	return err
+}
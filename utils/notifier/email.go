@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewEmailNotifier(host, port, username, password, from string) *EmailNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &EmailNotifier{
+		addr: fmt.Sprintf("%s:%s", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (n EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return fmt.Errorf("user %s has no email", notification.UserID)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", n.from)
+	fmt.Fprintf(&body, "To: %s\r\n", notification.Email)
+	if notification.Kind == KindUnblocked {
+		fmt.Fprintf(&body, "Subject: Unblocked: %s\r\n", notification.Title)
+		body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		fmt.Fprintf(&body, "Task %q is no longer blocked and can be started.\r\n", notification.Title)
+	} else {
+		fmt.Fprintf(&body, "Subject: Reminder: %s\r\n", notification.Title)
+		body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		fmt.Fprintf(&body, "Reminder for task %q.\r\n", notification.Title)
+	}
+	if notification.DueAt != nil {
+		fmt.Fprintf(&body, "Due at %s.\r\n", notification.DueAt.Format(time.RFC1123))
+	}
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{notification.Email}, []byte(body.String()))
+}
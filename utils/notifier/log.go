@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+	"main/utils/logging"
+)
+
+type LogNotifier struct {
+	logger *logging.Logger
+}
+
+func NewLogNotifier(logger *logging.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Kind == KindUnblocked {
+		n.logger.Infof("reminder %s: task %q of user %s is unblocked", notification.ReminderID, notification.Title, notification.UserID)
+		return nil
+	}
+
+	n.logger.Infof("reminder %s: task %q of user %s", notification.ReminderID, notification.Title, notification.UserID)
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"time"
+)
+
+// KindUnblocked is the kind of notifications sent for a task whose last
+// blocker was completed. Reminders set by the user have no kind.
+const KindUnblocked = "unblocked"
+
+type Notification struct {
+	ReminderID string     `json:"reminder_id"`
+	UserID     string     `json:"user_id"`
+	Email      string     `json:"email"`
+	TaskID     string     `json:"task_id"`
+	Kind       string     `json:"kind,omitempty"`
+	Title      string     `json:"title"`
+	DueAt      *time.Time `json:"due_at"`
+	FireAt     time.Time  `json:"fire_at"`
+}
+
+// Notifier delivers reminders. Delivery is at-least-once, so implementations
+// may see the same notification more than once.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
@@ -0,0 +1,104 @@
+package position
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keys are strings over digits that sort the same way byte-wise and in
+// MongoDB, so a new key can always be made between two existing ones and a
+// move rewrites only the moved document.
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// MaxLength is the key length after which a list should be rebalanced.
+const MaxLength = 24
+
+// Between returns a key that sorts strictly between a and b. An empty a means
+// the start of the list and an empty b the end of it.
+func Between(a, b string) (string, error) {
+	if b != "" && a >= b {
+		return "", fmt.Errorf("invalid position range %q..%q", a, b)
+	}
+
+	for _, key := range []string{a, b} {
+		for _, c := range key {
+			if !strings.ContainsRune(digits, c) {
+				return "", fmt.Errorf("invalid position %q", key)
+			}
+		}
+	}
+
+	// Every key starting with a sorts after a zero padded b, so nothing fits
+	// in between and the list has to be rebalanced.
+	if b != "" && strings.HasPrefix(b, a) && strings.TrimRight(b[len(a):], digits[:1]) == "" {
+		return "", fmt.Errorf("no position between %q and %q", a, b)
+	}
+
+	return midpoint(a, b), nil
+}
+
+// midpoint walks the common prefix of a and b and picks a digit half way
+// between the first digits that differ, extending the key when they are
+// adjacent.
+func midpoint(a, b string) string {
+	var key strings.Builder
+
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = strings.IndexByte(digits, a[i])
+		}
+
+		hi := base
+		if b != "" && i < len(b) {
+			hi = strings.IndexByte(digits, b[i])
+		}
+
+		if lo == hi {
+			key.WriteByte(digits[lo])
+			continue
+		}
+
+		if hi-lo > 1 {
+			key.WriteByte(digits[(lo+hi)/2])
+			return key.String()
+		}
+
+		// Adjacent digits: keep lo and look for room after it, with no upper
+		// bound from b any more.
+		key.WriteByte(digits[lo])
+		b = ""
+	}
+}
+
+// Spread returns n evenly spaced keys of equal length, used to rebalance a
+// list whose keys grew too long. The keys use at most half of the values of
+// their length, so there is room left to append after a rebalance.
+func Spread(n int) []string {
+	length := 1
+	for capacity := base - 1; capacity < 2*n; capacity *= base {
+		length++
+	}
+
+	total := 1
+	for i := 0; i < length; i++ {
+		total *= base
+	}
+
+	step := total / (n + 1)
+	keys := make([]string, n)
+
+	for i := range keys {
+		value := step * (i + 1)
+		key := make([]byte, length)
+		for j := length - 1; j >= 0; j-- {
+			key[j] = digits[value%base]
+			value /= base
+		}
+		keys[i] = string(key)
+	}
+
+	return keys
+}
@@ -0,0 +1,90 @@
+package position
+
+import "testing"
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"", ""},
+		{"", "V"},
+		{"V", ""},
+		{"1", "3"},
+		{"1", "2"},
+		{"1V", "1W"},
+		{"1", "101"},
+		{"1", "1001"},
+		{"a", "a1"},
+		{"az", "b"},
+		{"", "01"},
+		{"zz", ""},
+	}
+
+	for _, tt := range tests {
+		key, err := Between(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("Between(%q, %q) failed: %s", tt.a, tt.b, err)
+			continue
+		}
+
+		if key <= tt.a || (tt.b != "" && key >= tt.b) {
+			t.Errorf("Between(%q, %q) = %q, want a key strictly in between", tt.a, tt.b, key)
+		}
+	}
+}
+
+func TestBetweenWithoutRoom(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"1", "10"},
+		{"a", "a0"},
+		{"a", "a000"},
+		{"", "0"},
+		{"", "00"},
+		{"b", "a"},
+		{"a", "a"},
+		{"a", "a-"},
+	}
+
+	for _, tt := range tests {
+		if key, err := Between(tt.a, tt.b); err == nil {
+			t.Errorf("Between(%q, %q) = %q, want an error", tt.a, tt.b, key)
+		}
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{1, 2, 61, 62, 1000} {
+		keys := Spread(n)
+		if len(keys) != n {
+			t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+		}
+
+		for i, key := range keys {
+			if len(key) != len(keys[0]) {
+				t.Errorf("Spread(%d) key %q has not the length of %q", n, key, keys[0])
+			}
+			if i > 0 && key <= keys[i-1] {
+				t.Errorf("Spread(%d) keys %q and %q are out of order", n, keys[i-1], key)
+			}
+			if _, err := Between("", key); err != nil {
+				t.Errorf("Spread(%d) key %q leaves no room before it", n, key)
+			}
+		}
+	}
+}
+
+func TestAppendAfterSpread(t *testing.T) {
+	for _, n := range []int{1, 61, 1000} {
+		keys := Spread(n)
+
+		key, err := Between(keys[n-1], "")
+		if err != nil {
+			t.Fatalf("Spread(%d) leaves no room after the last key: %s", n, err)
+		}
+		if len(key) > len(keys[n-1]) {
+			t.Errorf("Spread(%d) appending after %q gave the longer key %q", n, keys[n-1], key)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+// maxPeriods bounds expansion of rules that can never match, like
+// FREQ=MONTHLY;BYMONTHDAY=31 combined with BYDAY that never falls on the 31st.
+const maxPeriods = 5000
+
+var frequencies = map[string]Frequency{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+	"YEARLY":  Yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum is a BYDAY entry. N is the ordinal inside the month (1 for the
+// first, -1 for the last), zero means every such weekday.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule is the subset of RFC 5545 RRULE supported for tasks.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	WeekStart  time.Weekday
+
+	// untilDate is set when UNTIL is a date without a time.
+	untilDate bool
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// The "RRULE:" prefix is optional.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	hasFreq := false
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq, ok := frequencies[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+			r.Freq = freq
+			hasFreq = true
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.Count = count
+		case "UNTIL":
+			until, date, err := parseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", value)
+			}
+			r.Until, r.untilDate = until, date
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				day, err := parseWeekdayNum(v)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				day, err := strconv.Atoi(v)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, day)
+			}
+		case "WKST":
+			wd, ok := weekdays[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("invalid WKST %q", value)
+			}
+			r.WeekStart = wd
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if !hasFreq {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL are mutually exclusive")
+	}
+
+	if r.Freq == Yearly && (len(r.ByDay) > 0 || len(r.ByMonthDay) > 0) {
+		return nil, fmt.Errorf("BYDAY and BYMONTHDAY are not supported with YEARLY")
+	}
+
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != Monthly {
+			return nil, fmt.Errorf("BYDAY ordinals are only allowed with MONTHLY")
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return nil, fmt.Errorf("BYMONTHDAY is not allowed with WEEKLY")
+	}
+
+	return r, nil
+}
+
+func parseUntil(value string) (until time.Time, date bool, err error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, layout == "20060102", nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("unknown format")
+}
+
+func parseWeekdayNum(v string) (WeekdayNum, error) {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if len(v) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+
+	wd, ok := weekdays[v[len(v)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+
+	n := 0
+	if prefix := v[:len(v)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+		}
+	}
+
+	return WeekdayNum{N: n, Weekday: wd}, nil
+}
+
+// All returns up to limit occurrences of the rule from dtstart on. Unlike
+// RFC 5545, dtstart is only the first occurrence when it matches the rule.
+func (r *Rule) All(dtstart time.Time, limit int) []time.Time {
+	var occurrences []time.Time
+
+	r.iterate(dtstart, func(t time.Time, _ int) bool {
+		occurrences = append(occurrences, t)
+		return len(occurrences) < limit
+	})
+
+	return occurrences
+}
+
+// Next returns the first occurrence strictly after the given time together
+// with its 1-based index in the series. ok is false once the series ends.
+func (r *Rule) Next(dtstart, after time.Time) (next time.Time, n int, ok bool) {
+	r.iterate(dtstart, func(t time.Time, i int) bool {
+		if t.After(after) {
+			next, n, ok = t, i, true
+			return false
+		}
+		return true
+	})
+
+	return next, n, ok
+}
+
+// iterate calls fn for every occurrence in order until fn returns false or
+// the series ends.
+func (r *Rule) iterate(dtstart time.Time, fn func(t time.Time, n int) bool) {
+	n := 0
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period) {
+			if t.Before(dtstart) {
+				continue
+			}
+
+			if r.PastUntil(t) {
+				return
+			}
+
+			n++
+			if !fn(t, n) {
+				return
+			}
+
+			if r.Count > 0 && n >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// PastUntil reports whether t is after the last time allowed by UNTIL. A
+// date-only UNTIL includes the whole day, in the time zone of t.
+func (r *Rule) PastUntil(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+
+	if r.untilDate {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+
+	return t.After(r.Until)
+}
+
+// candidates returns the sorted occurrences that fall into the given period
+// counted from dtstart in units of FREQ*INTERVAL.
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	hour, min, sec := dtstart.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, min, sec, dtstart.Nanosecond(), loc)
+	}
+
+	var result []time.Time
+
+	switch r.Freq {
+	case Daily:
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+period*r.Interval)
+		if r.matchDay(day) {
+			result = append(result, day)
+		}
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+period*r.Interval*7)
+
+		for i := 0; i < 7; i++ {
+			day := at(weekStart.Year(), weekStart.Month(), weekStart.Day()+i)
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matchDay(day) {
+				result = append(result, day)
+			}
+		}
+	case Monthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(period*r.Interval), 1, 0, 0, 0, 0, loc)
+		result = r.monthCandidates(first, dtstart, at)
+	case Yearly:
+		day := at(dtstart.Year()+period*r.Interval, dtstart.Month(), dtstart.Day())
+		if day.Day() == dtstart.Day() {
+			result = append(result, day)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+
+	return result
+}
+
+func (r *Rule) monthCandidates(first, dtstart time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	daysInMonth := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, first.Location()).Day()
+
+	var result []time.Time
+
+	for d := 1; d <= daysInMonth; d++ {
+		day := at(first.Year(), first.Month(), d)
+
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if d == dtstart.Day() {
+				result = append(result, day)
+			}
+			continue
+		}
+
+		if len(r.ByMonthDay) > 0 && !containsMonthDay(r.ByMonthDay, d, daysInMonth) {
+			continue
+		}
+
+		if len(r.ByDay) > 0 && !r.matchMonthWeekday(day, daysInMonth) {
+			continue
+		}
+
+		result = append(result, day)
+	}
+
+	return result
+}
+
+func (r *Rule) matchDay(day time.Time) bool {
+	if len(r.ByMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		if !containsMonthDay(r.ByMonthDay, day.Day(), daysInMonth) {
+			return false
+		}
+	}
+
+	if len(r.ByDay) == 0 {
+		return true
+	}
+
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) matchMonthWeekday(day time.Time, daysInMonth int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (day.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (daysInMonth-day.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsMonthDay(days []int, day, daysInMonth int) bool {
+	for _, d := range days {
+		if d == day || (d < 0 && daysInMonth+d+1 == day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Step returns the time one FREQ*INTERVAL after t. It is used for tasks
+// that repeat relative to their completion instead of a fixed calendar.
+func (r *Rule) Step(t time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return t.AddDate(0, 0, 7*r.Interval)
+	case Monthly:
+		return t.AddDate(0, r.Interval, 0)
+	case Yearly:
+		return t.AddDate(r.Interval, 0, 0)
+	default:
+		return t.AddDate(0, 0, r.Interval)
+	}
+}
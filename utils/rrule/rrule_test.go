@@ -0,0 +1,201 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{"empty", ""},
+		{"missing FREQ", "INTERVAL=2"},
+		{"bad FREQ", "FREQ=HOURLY"},
+		{"bad BYDAY", "FREQ=WEEKLY;BYDAY=XX"},
+		{"bad BYDAY ordinal", "FREQ=MONTHLY;BYDAY=6MO"},
+		{"BYDAY ordinal outside MONTHLY", "FREQ=WEEKLY;BYDAY=1MO"},
+		{"zero INTERVAL", "FREQ=DAILY;INTERVAL=0"},
+		{"bad INTERVAL", "FREQ=DAILY;INTERVAL=two"},
+		{"bad BYMONTHDAY", "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{"bad UNTIL", "FREQ=DAILY;UNTIL=tomorrow"},
+		{"COUNT with UNTIL", "FREQ=DAILY;COUNT=2;UNTIL=20240101"},
+		{"unknown part", "FREQ=DAILY;BYHOUR=9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.rule); err == nil {
+				t.Errorf("Parse(%q) succeeded, want an error", tt.rule)
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		limit   int
+		want    []time.Time
+	}{
+		{
+			name:    "daily with COUNT",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: date(2024, time.January, 1),
+			limit:   10,
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.January, 2), date(2024, time.January, 3)},
+		},
+		{
+			name:    "daily with INTERVAL",
+			rule:    "RRULE:FREQ=DAILY;INTERVAL=2;COUNT=3",
+			dtstart: date(2024, time.January, 1),
+			limit:   10,
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.January, 3), date(2024, time.January, 5)},
+		},
+		{
+			name:    "UNTIL with time excludes later occurrences that day",
+			rule:    "FREQ=DAILY;UNTIL=20240103T000000Z",
+			dtstart: date(2024, time.January, 1),
+			limit:   10,
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.January, 2)},
+		},
+		{
+			name:    "date-only UNTIL includes the whole day",
+			rule:    "FREQ=DAILY;UNTIL=20240103",
+			dtstart: date(2024, time.January, 1),
+			limit:   10,
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.January, 2), date(2024, time.January, 3)},
+		},
+		{
+			name:    "date-only UNTIL in the time zone of dtstart",
+			rule:    "FREQ=DAILY;UNTIL=20240102",
+			dtstart: time.Date(2024, time.January, 1, 22, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+			limit:   10,
+			want: []time.Time{
+				time.Date(2024, time.January, 1, 22, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+				time.Date(2024, time.January, 2, 22, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+			},
+		},
+		{
+			name:    "weekly BYDAY",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE",
+			dtstart: date(2024, time.January, 1),
+			limit:   4,
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.January, 3), date(2024, time.January, 8), date(2024, time.January, 10)},
+		},
+		{
+			name:    "weekly with INTERVAL",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			dtstart: date(2024, time.January, 2),
+			limit:   3,
+			want:    []time.Time{date(2024, time.January, 2), date(2024, time.January, 16), date(2024, time.January, 30)},
+		},
+		{
+			name:    "dtstart not matching the rule is skipped",
+			rule:    "FREQ=WEEKLY;BYDAY=MO",
+			dtstart: date(2024, time.January, 2),
+			limit:   2,
+			want:    []time.Time{date(2024, time.January, 8), date(2024, time.January, 15)},
+		},
+		{
+			name:    "last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: date(2024, time.January, 1),
+			limit:   3,
+			want:    []time.Time{date(2024, time.January, 26), date(2024, time.February, 23), date(2024, time.March, 29)},
+		},
+		{
+			name:    "second monday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=2MO",
+			dtstart: date(2024, time.January, 1),
+			limit:   3,
+			want:    []time.Time{date(2024, time.January, 8), date(2024, time.February, 12), date(2024, time.March, 11)},
+		},
+		{
+			name:    "BYMONTHDAY=31 skips short months",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=31",
+			dtstart: date(2024, time.January, 31),
+			limit:   4,
+			want:    []time.Time{date(2024, time.January, 31), date(2024, time.March, 31), date(2024, time.May, 31), date(2024, time.July, 31)},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: date(2024, time.January, 31),
+			limit:   3,
+			want:    []time.Time{date(2024, time.January, 31), date(2024, time.February, 29), date(2024, time.March, 31)},
+		},
+		{
+			name:    "yearly on february 29 skips common years",
+			rule:    "FREQ=YEARLY",
+			dtstart: date(2024, time.February, 29),
+			limit:   2,
+			want:    []time.Time{date(2024, time.February, 29), date(2028, time.February, 29)},
+		},
+		{
+			name:    "rule that never matches ends",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=31;BYDAY=1MO",
+			dtstart: date(2024, time.January, 1),
+			limit:   1,
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %s", tt.rule, err)
+			}
+
+			got := rule.All(tt.dtstart, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("All() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Fatalf("All() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		after  time.Time
+		want   time.Time
+		wantN  int
+		wantOK bool
+	}{
+		{"before dtstart", "FREQ=DAILY", date(2024, time.January, 1).Add(-time.Hour), date(2024, time.January, 1), 1, true},
+		{"strictly after dtstart", "FREQ=DAILY", date(2024, time.January, 1), date(2024, time.January, 2), 2, true},
+		{"strictly after an occurrence", "FREQ=DAILY;INTERVAL=3", date(2024, time.January, 4), date(2024, time.January, 7), 3, true},
+		{"between occurrences", "FREQ=WEEKLY;BYDAY=FR", date(2024, time.January, 6), date(2024, time.January, 12), 2, true},
+		{"series ended by COUNT", "FREQ=DAILY;COUNT=2", date(2024, time.January, 2), time.Time{}, 0, false},
+		{"series ended by UNTIL", "FREQ=DAILY;UNTIL=20240102", date(2024, time.January, 2), time.Time{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %s", tt.rule, err)
+			}
+
+			got, n, ok := rule.Next(date(2024, time.January, 1), tt.after)
+			if ok != tt.wantOK || n != tt.wantN || !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, %d, %t, want %v, %d, %t", got, n, ok, tt.want, tt.wantN, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"main/utils/logging"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const lockKey = "scheduler:leader"
+
+// renewScript extends the lock only while it is still held by this instance.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Scheduler runs jobs periodically on a single instance. Instances compete
+// for a Redis lock and only the current holder runs the jobs.
+type Scheduler struct {
+	redis    *redis.Client
+	logger   *logging.Logger
+	id       string
+	interval time.Duration
+	ttl      time.Duration
+	jobs     []Job
+	leader   bool
+}
+
+func NewScheduler(rdb *redis.Client, interval time.Duration, logger *logging.Logger) *Scheduler {
+	return &Scheduler{
+		redis:    rdb,
+		logger:   logger,
+		id:       uuid.NewString(),
+		interval: interval,
+		ttl:      3 * interval,
+	}
+}
+
+func (s *Scheduler) AddJob(name string, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Run: run})
+}
+
+// Run blocks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if s.elect(ctx) {
+			for _, job := range s.jobs {
+				if err := job.Run(ctx); err != nil {
+					s.logger.Errorf("scheduler job %s failed: %s", job.Name, err.Error())
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) elect(ctx context.Context) bool {
+	if s.leader {
+		renewed, err := renewScript.Run(ctx, s.redis, []string{lockKey}, s.id, s.ttl.Milliseconds()).Int()
+		if err == nil && renewed == 1 {
+			return true
+		}
+
+		s.leader = false
+		s.logger.Info("Scheduler lost leadership")
+	}
+
+	acquired, err := s.redis.SetNX(ctx, lockKey, s.id, s.ttl).Result()
+	if err != nil {
+		s.logger.Errorf("scheduler can not acquire lock: %s", err.Error())
+		return false
+	}
+
+	if acquired {
+		s.leader = true
+		s.logger.Info("Scheduler became leader")
+	}
+
+	return s.leader
+}
+
+func (s *Scheduler) release() {
+	if !s.leader {
+		return
+	}
+
+	// Shorten the lock to a millisecond so another instance can take over
+	// without waiting for the TTL.
+	renewScript.Run(context.Background(), s.redis, []string{lockKey}, s.id, 1)
+	s.leader = false
+}